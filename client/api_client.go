@@ -1,186 +1,599 @@
 package client
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"log/slog"
+	"sync"
 	"time"
 
 	"go-testify-allure-api-test/config"
+	"go-testify-allure-api-test/logging"
 	"go-testify-allure-api-test/models"
 
 	"github.com/go-resty/resty/v2"
 )
 
-// APIClient API客户端结构体
+// requestIDHeader 每个请求携带的请求ID头
+const requestIDHeader = "X-Request-ID"
+
+// APIClient API客户端结构体。
+// 创建完成后可在多个 goroutine 中并发使用；选项只在 New 中应用，之后不再修改底层 resty 客户端
 type APIClient struct {
-	client  *resty.Client
-	baseURL string
+	client   *resty.Client
+	baseURL  string
+	logger   *slog.Logger
+	testName string
+	cleanup  *Cleanup
+	hooks    []Hook
+	retry    RetryPolicy
+	limiter  *Limiter
+	breaker  *Breaker
+	faults   *FaultInjector
+	drift    StrictMode
+
+	mu    sync.RWMutex
+	token string
 }
 
-// NewAPIClient 创建新的API客户端
+// NewAPIClient 使用全局配置创建新的API客户端
 func NewAPIClient() *APIClient {
-	cfg := config.GetConfig()
-	
+	return New(config.GetConfig())
+}
+
+// New 使用指定配置创建API客户端，cfg 为nil时使用全局配置，opts 在配置之后生效。
+// 请求日志默认写入按全局配置创建的记录器，可通过 WithLogger 替换
+func New(cfg *config.Config, opts ...Option) *APIClient {
+	if cfg == nil {
+		cfg = config.GetConfig()
+	}
+
 	client := resty.New()
 	client.SetBaseURL(cfg.API.BaseURL)
 	client.SetTimeout(time.Duration(cfg.API.Timeout) * time.Second)
-	client.SetRetryCount(cfg.API.RetryCount)
-	client.SetRetryWaitTime(1 * time.Second)
-	client.SetRetryMaxWaitTime(5 * time.Second)
-	
+	// 记录 DNS、连接、TLS 和首字节等各阶段耗时，供 HAR 等报告使用
+	client.EnableTrace()
+
 	// 设置通用请求头
 	client.SetHeaders(map[string]string{
 		"Content-Type": "application/json",
 		"Accept":       "application/json",
 	})
-	
-	return &APIClient{
+
+	c := &APIClient{
 		client:  client,
 		baseURL: cfg.API.BaseURL,
+		logger:  logging.Default(),
+		retry:   NewRetryPolicy(cfg),
+		limiter: SharedLimiter(),
+		breaker: SharedBreaker(),
+		faults:  NewFaultInjectorFromConfig(cfg),
+		drift:   StrictMode(cfg.API.StrictDecoding),
+	}
+
+	// 为每个请求生成请求ID，便于在服务端日志和 APIError 中定位；
+	// 认证令牌按请求设置，避免并发请求期间修改共享的 resty 客户端
+	client.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+		if req.Header.Get(requestIDHeader) == "" {
+			req.SetHeader(requestIDHeader, newRequestID())
+		}
+		if token := c.authToken(); token != "" && req.Token == "" {
+			req.SetAuthToken(token)
+		}
+		return nil
+	})
+	c.registerLogging()
+	c.registerDrift()
+	c.registerHooks()
+	c.registerRetry()
+	for _, opt := range opts {
+		opt(c)
 	}
+	c.applyRetry()
+	c.applyFaults()
+	c.applyLimiter()
+	c.applyBreaker()
+	return c
+}
+
+// BaseURL 返回客户端请求的基础地址
+func (c *APIClient) BaseURL() string {
+	return c.baseURL
 }
 
-// SetAuthToken 设置认证令牌
+// SetAuthToken 设置认证令牌，对之后发出的请求生效，可与请求并发调用
 func (c *APIClient) SetAuthToken(token string) {
-	c.client.SetAuthToken(token)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// authToken 返回当前的认证令牌
+func (c *APIClient) authToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// newRequestID 生成随机请求ID
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newRequest 创建绑定 ctx 的请求，并记录请求的每次尝试，供 Attempts 读取
+func (c *APIClient) newRequest(ctx context.Context) *resty.Request {
+	return c.client.R().SetContext(withAttemptLog(ctx))
 }
 
 // GetAllProducts 获取所有商品
 func (c *APIClient) GetAllProducts() ([]models.Product, *resty.Response, error) {
+	return c.GetAllProductsWithContext(context.Background())
+}
+
+// GetAllProductsWithContext 获取所有商品，请求受 ctx 控制
+func (c *APIClient) GetAllProductsWithContext(ctx context.Context) ([]models.Product, *resty.Response, error) {
 	var products []models.Product
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetResult(&products).
 		Get("/products")
-	return products, resp, err
+	return products, resp, CheckResponse(ctx, resp, err)
 }
 
 // GetProductByID 根据ID获取商品
 func (c *APIClient) GetProductByID(id int) (*models.Product, *resty.Response, error) {
+	return c.GetProductByIDWithContext(context.Background(), id)
+}
+
+// GetProductByIDWithContext 根据ID获取商品，请求受 ctx 控制
+func (c *APIClient) GetProductByIDWithContext(ctx context.Context, id int) (*models.Product, *resty.Response, error) {
 	var product models.Product
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetResult(&product).
 		Get(fmt.Sprintf("/products/%d", id))
-	return &product, resp, err
+	return &product, resp, CheckResponse(ctx, resp, err)
 }
 
 // GetProductsByLimit 获取限定数量的商品
 func (c *APIClient) GetProductsByLimit(limit int) ([]models.Product, *resty.Response, error) {
+	return c.GetProductsByLimitWithContext(context.Background(), limit)
+}
+
+// GetProductsByLimitWithContext 获取限定数量的商品，请求受 ctx 控制
+func (c *APIClient) GetProductsByLimitWithContext(ctx context.Context, limit int) ([]models.Product, *resty.Response, error) {
 	var products []models.Product
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetQueryParam("limit", fmt.Sprintf("%d", limit)).
 		SetResult(&products).
 		Get("/products")
-	return products, resp, err
+	return products, resp, CheckResponse(ctx, resp, err)
 }
 
 // GetProductsBySort 获取排序后的商品
 func (c *APIClient) GetProductsBySort(sort string) ([]models.Product, *resty.Response, error) {
+	return c.GetProductsBySortWithContext(context.Background(), sort)
+}
+
+// GetProductsBySortWithContext 获取排序后的商品，请求受 ctx 控制
+func (c *APIClient) GetProductsBySortWithContext(ctx context.Context, sort string) ([]models.Product, *resty.Response, error) {
 	var products []models.Product
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetQueryParam("sort", sort).
 		SetResult(&products).
 		Get("/products")
-	return products, resp, err
+	return products, resp, CheckResponse(ctx, resp, err)
 }
 
 // GetAllCategories 获取所有商品分类
 func (c *APIClient) GetAllCategories() ([]string, *resty.Response, error) {
+	return c.GetAllCategoriesWithContext(context.Background())
+}
+
+// GetAllCategoriesWithContext 获取所有商品分类，请求受 ctx 控制
+func (c *APIClient) GetAllCategoriesWithContext(ctx context.Context) ([]string, *resty.Response, error) {
 	var categories []string
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetResult(&categories).
 		Get("/products/categories")
-	return categories, resp, err
+	return categories, resp, CheckResponse(ctx, resp, err)
 }
 
 // GetProductsByCategory 根据分类获取商品
 func (c *APIClient) GetProductsByCategory(category string) ([]models.Product, *resty.Response, error) {
+	return c.GetProductsByCategoryWithContext(context.Background(), category)
+}
+
+// GetProductsByCategoryWithContext 根据分类获取商品，请求受 ctx 控制
+func (c *APIClient) GetProductsByCategoryWithContext(ctx context.Context, category string) ([]models.Product, *resty.Response, error) {
 	var products []models.Product
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetResult(&products).
 		Get(fmt.Sprintf("/products/category/%s", category))
-	return products, resp, err
+	return products, resp, CheckResponse(ctx, resp, err)
 }
 
 // CreateProduct 创建新商品
 func (c *APIClient) CreateProduct(product models.CreateProductRequest) (*models.Product, *resty.Response, error) {
+	return c.CreateProductWithContext(context.Background(), product)
+}
+
+// CreateProductWithContext 创建新商品，请求受 ctx 控制
+func (c *APIClient) CreateProductWithContext(ctx context.Context, product models.CreateProductRequest) (*models.Product, *resty.Response, error) {
 	var result models.Product
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetBody(product).
 		SetResult(&result).
 		Post("/products")
+	if err = CheckResponse(ctx, resp, err); err == nil {
+		c.trackCreated(fmt.Sprintf("/products/%d", result.ID))
+	}
 	return &result, resp, err
 }
 
 // UpdateProduct 更新商品
 func (c *APIClient) UpdateProduct(id int, product models.UpdateProductRequest) (*models.Product, *resty.Response, error) {
+	return c.UpdateProductWithContext(context.Background(), id, product)
+}
+
+// UpdateProductWithContext 更新商品，请求受 ctx 控制
+func (c *APIClient) UpdateProductWithContext(ctx context.Context, id int, product models.UpdateProductRequest) (*models.Product, *resty.Response, error) {
+	path := fmt.Sprintf("/products/%d", id)
+	c.beforeMutate(ctx, path)
+
 	var result models.Product
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetBody(product).
 		SetResult(&result).
-		Put(fmt.Sprintf("/products/%d", id))
-	return &result, resp, err
+		Put(path)
+	return &result, resp, CheckResponse(ctx, resp, err)
 }
 
 // PatchProduct 部分更新商品
 func (c *APIClient) PatchProduct(id int, product models.UpdateProductRequest) (*models.Product, *resty.Response, error) {
+	return c.PatchProductWithContext(context.Background(), id, product)
+}
+
+// PatchProductWithContext 部分更新商品，请求受 ctx 控制
+func (c *APIClient) PatchProductWithContext(ctx context.Context, id int, product models.UpdateProductRequest) (*models.Product, *resty.Response, error) {
+	path := fmt.Sprintf("/products/%d", id)
+	c.beforeMutate(ctx, path)
+
 	var result models.Product
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetBody(product).
 		SetResult(&result).
-		Patch(fmt.Sprintf("/products/%d", id))
-	return &result, resp, err
+		Patch(path)
+	return &result, resp, CheckResponse(ctx, resp, err)
 }
 
 // DeleteProduct 删除商品
 func (c *APIClient) DeleteProduct(id int) (*models.Product, *resty.Response, error) {
+	return c.DeleteProductWithContext(context.Background(), id)
+}
+
+// DeleteProductWithContext 删除商品，请求受 ctx 控制
+func (c *APIClient) DeleteProductWithContext(ctx context.Context, id int) (*models.Product, *resty.Response, error) {
+	path := fmt.Sprintf("/products/%d", id)
+	c.beforeMutate(ctx, path)
+
 	var result models.Product
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetResult(&result).
-		Delete(fmt.Sprintf("/products/%d", id))
+		Delete(path)
+	if err = CheckResponse(ctx, resp, err); err == nil {
+		c.trackDeleted(path)
+	}
 	return &result, resp, err
 }
 
 // GetAllCarts 获取所有购物车
 func (c *APIClient) GetAllCarts() ([]models.Cart, *resty.Response, error) {
+	return c.GetAllCartsWithContext(context.Background())
+}
+
+// GetAllCartsWithContext 获取所有购物车，请求受 ctx 控制
+func (c *APIClient) GetAllCartsWithContext(ctx context.Context) ([]models.Cart, *resty.Response, error) {
 	var carts []models.Cart
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetResult(&carts).
 		Get("/carts")
-	return carts, resp, err
+	return carts, resp, CheckResponse(ctx, resp, err)
 }
 
 // GetCartByID 根据ID获取购物车
 func (c *APIClient) GetCartByID(id int) (*models.Cart, *resty.Response, error) {
+	return c.GetCartByIDWithContext(context.Background(), id)
+}
+
+// GetCartByIDWithContext 根据ID获取购物车，请求受 ctx 控制
+func (c *APIClient) GetCartByIDWithContext(ctx context.Context, id int) (*models.Cart, *resty.Response, error) {
 	var cart models.Cart
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetResult(&cart).
 		Get(fmt.Sprintf("/carts/%d", id))
-	return &cart, resp, err
+	return &cart, resp, CheckResponse(ctx, resp, err)
+}
+
+// GetCartsByLimit 获取限定数量的购物车
+func (c *APIClient) GetCartsByLimit(limit int) ([]models.Cart, *resty.Response, error) {
+	return c.GetCartsByLimitWithContext(context.Background(), limit)
+}
+
+// GetCartsByLimitWithContext 获取限定数量的购物车，请求受 ctx 控制
+func (c *APIClient) GetCartsByLimitWithContext(ctx context.Context, limit int) ([]models.Cart, *resty.Response, error) {
+	var carts []models.Cart
+	resp, err := c.newRequest(ctx).
+		SetQueryParam("limit", fmt.Sprintf("%d", limit)).
+		SetResult(&carts).
+		Get("/carts")
+	return carts, resp, CheckResponse(ctx, resp, err)
+}
+
+// GetCartsBySort 获取排序后的购物车
+func (c *APIClient) GetCartsBySort(sort string) ([]models.Cart, *resty.Response, error) {
+	return c.GetCartsBySortWithContext(context.Background(), sort)
+}
+
+// GetCartsBySortWithContext 获取排序后的购物车，请求受 ctx 控制
+func (c *APIClient) GetCartsBySortWithContext(ctx context.Context, sort string) ([]models.Cart, *resty.Response, error) {
+	var carts []models.Cart
+	resp, err := c.newRequest(ctx).
+		SetQueryParam("sort", sort).
+		SetResult(&carts).
+		Get("/carts")
+	return carts, resp, CheckResponse(ctx, resp, err)
+}
+
+// GetCartsByDateRange 获取指定日期范围内的购物车，起止日期均包含在内
+func (c *APIClient) GetCartsByDateRange(startDate, endDate time.Time) ([]models.Cart, *resty.Response, error) {
+	return c.GetCartsByDateRangeWithContext(context.Background(), startDate, endDate)
+}
+
+// GetCartsByDateRangeWithContext 获取指定日期范围内的购物车，请求受 ctx 控制
+func (c *APIClient) GetCartsByDateRangeWithContext(ctx context.Context, startDate, endDate time.Time) ([]models.Cart, *resty.Response, error) {
+	var carts []models.Cart
+	resp, err := c.newRequest(ctx).
+		SetQueryParam("startdate", startDate.Format(models.DateLayout)).
+		SetQueryParam("enddate", endDate.Format(models.DateLayout)).
+		SetResult(&carts).
+		Get("/carts")
+	return carts, resp, CheckResponse(ctx, resp, err)
+}
+
+// GetCartsByUserID 获取指定用户的购物车
+func (c *APIClient) GetCartsByUserID(userID int) ([]models.Cart, *resty.Response, error) {
+	return c.GetCartsByUserIDWithContext(context.Background(), userID)
+}
+
+// GetCartsByUserIDWithContext 获取指定用户的购物车，请求受 ctx 控制
+func (c *APIClient) GetCartsByUserIDWithContext(ctx context.Context, userID int) ([]models.Cart, *resty.Response, error) {
+	var carts []models.Cart
+	resp, err := c.newRequest(ctx).
+		SetResult(&carts).
+		Get(fmt.Sprintf("/carts/user/%d", userID))
+	return carts, resp, CheckResponse(ctx, resp, err)
+}
+
+// CreateCart 创建新购物车
+func (c *APIClient) CreateCart(cart models.CreateCartRequest) (*models.Cart, *resty.Response, error) {
+	return c.CreateCartWithContext(context.Background(), cart)
+}
+
+// CreateCartWithContext 创建新购物车，请求受 ctx 控制
+func (c *APIClient) CreateCartWithContext(ctx context.Context, cart models.CreateCartRequest) (*models.Cart, *resty.Response, error) {
+	var result models.Cart
+	resp, err := c.newRequest(ctx).
+		SetBody(cart).
+		SetResult(&result).
+		Post("/carts")
+	if err = CheckResponse(ctx, resp, err); err == nil {
+		c.trackCreated(fmt.Sprintf("/carts/%d", result.ID))
+	}
+	return &result, resp, err
+}
+
+// UpdateCart 更新购物车
+func (c *APIClient) UpdateCart(id int, cart models.UpdateCartRequest) (*models.Cart, *resty.Response, error) {
+	return c.UpdateCartWithContext(context.Background(), id, cart)
+}
+
+// UpdateCartWithContext 更新购物车，请求受 ctx 控制
+func (c *APIClient) UpdateCartWithContext(ctx context.Context, id int, cart models.UpdateCartRequest) (*models.Cart, *resty.Response, error) {
+	path := fmt.Sprintf("/carts/%d", id)
+	c.beforeMutate(ctx, path)
+
+	var result models.Cart
+	resp, err := c.newRequest(ctx).
+		SetBody(cart).
+		SetResult(&result).
+		Put(path)
+	return &result, resp, CheckResponse(ctx, resp, err)
+}
+
+// PatchCart 部分更新购物车
+func (c *APIClient) PatchCart(id int, cart models.UpdateCartRequest) (*models.Cart, *resty.Response, error) {
+	return c.PatchCartWithContext(context.Background(), id, cart)
+}
+
+// PatchCartWithContext 部分更新购物车，请求受 ctx 控制
+func (c *APIClient) PatchCartWithContext(ctx context.Context, id int, cart models.UpdateCartRequest) (*models.Cart, *resty.Response, error) {
+	path := fmt.Sprintf("/carts/%d", id)
+	c.beforeMutate(ctx, path)
+
+	var result models.Cart
+	resp, err := c.newRequest(ctx).
+		SetBody(cart).
+		SetResult(&result).
+		Patch(path)
+	return &result, resp, CheckResponse(ctx, resp, err)
+}
+
+// DeleteCart 删除购物车
+func (c *APIClient) DeleteCart(id int) (*models.Cart, *resty.Response, error) {
+	return c.DeleteCartWithContext(context.Background(), id)
+}
+
+// DeleteCartWithContext 删除购物车，请求受 ctx 控制
+func (c *APIClient) DeleteCartWithContext(ctx context.Context, id int) (*models.Cart, *resty.Response, error) {
+	path := fmt.Sprintf("/carts/%d", id)
+	c.beforeMutate(ctx, path)
+
+	var result models.Cart
+	resp, err := c.newRequest(ctx).
+		SetResult(&result).
+		Delete(path)
+	if err = CheckResponse(ctx, resp, err); err == nil {
+		c.trackDeleted(path)
+	}
+	return &result, resp, err
 }
 
 // GetAllUsers 获取所有用户
 func (c *APIClient) GetAllUsers() ([]models.User, *resty.Response, error) {
+	return c.GetAllUsersWithContext(context.Background())
+}
+
+// GetAllUsersWithContext 获取所有用户，请求受 ctx 控制
+func (c *APIClient) GetAllUsersWithContext(ctx context.Context) ([]models.User, *resty.Response, error) {
 	var users []models.User
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetResult(&users).
 		Get("/users")
-	return users, resp, err
+	return users, resp, CheckResponse(ctx, resp, err)
 }
 
 // GetUserByID 根据ID获取用户
 func (c *APIClient) GetUserByID(id int) (*models.User, *resty.Response, error) {
+	return c.GetUserByIDWithContext(context.Background(), id)
+}
+
+// GetUserByIDWithContext 根据ID获取用户，请求受 ctx 控制
+func (c *APIClient) GetUserByIDWithContext(ctx context.Context, id int) (*models.User, *resty.Response, error) {
 	var user models.User
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetResult(&user).
 		Get(fmt.Sprintf("/users/%d", id))
-	return &user, resp, err
+	return &user, resp, CheckResponse(ctx, resp, err)
+}
+
+// GetUsersByLimit 获取限定数量的用户
+func (c *APIClient) GetUsersByLimit(limit int) ([]models.User, *resty.Response, error) {
+	return c.GetUsersByLimitWithContext(context.Background(), limit)
+}
+
+// GetUsersByLimitWithContext 获取限定数量的用户，请求受 ctx 控制
+func (c *APIClient) GetUsersByLimitWithContext(ctx context.Context, limit int) ([]models.User, *resty.Response, error) {
+	var users []models.User
+	resp, err := c.newRequest(ctx).
+		SetQueryParam("limit", fmt.Sprintf("%d", limit)).
+		SetResult(&users).
+		Get("/users")
+	return users, resp, CheckResponse(ctx, resp, err)
+}
+
+// GetUsersBySort 获取排序后的用户
+func (c *APIClient) GetUsersBySort(sort string) ([]models.User, *resty.Response, error) {
+	return c.GetUsersBySortWithContext(context.Background(), sort)
+}
+
+// GetUsersBySortWithContext 获取排序后的用户，请求受 ctx 控制
+func (c *APIClient) GetUsersBySortWithContext(ctx context.Context, sort string) ([]models.User, *resty.Response, error) {
+	var users []models.User
+	resp, err := c.newRequest(ctx).
+		SetQueryParam("sort", sort).
+		SetResult(&users).
+		Get("/users")
+	return users, resp, CheckResponse(ctx, resp, err)
+}
+
+// AddUser 注册新用户
+func (c *APIClient) AddUser(user models.CreateUserRequest) (*models.User, *resty.Response, error) {
+	return c.AddUserWithContext(context.Background(), user)
+}
+
+// AddUserWithContext 注册新用户，请求受 ctx 控制
+func (c *APIClient) AddUserWithContext(ctx context.Context, user models.CreateUserRequest) (*models.User, *resty.Response, error) {
+	var result models.User
+	resp, err := c.newRequest(ctx).
+		SetBody(user).
+		SetResult(&result).
+		Post("/users")
+	if err = CheckResponse(ctx, resp, err); err == nil {
+		c.trackCreated(fmt.Sprintf("/users/%d", result.ID))
+	}
+	return &result, resp, err
+}
+
+// UpdateUser 更新用户
+func (c *APIClient) UpdateUser(id int, user models.UpdateUserRequest) (*models.User, *resty.Response, error) {
+	return c.UpdateUserWithContext(context.Background(), id, user)
+}
+
+// UpdateUserWithContext 更新用户，请求受 ctx 控制
+func (c *APIClient) UpdateUserWithContext(ctx context.Context, id int, user models.UpdateUserRequest) (*models.User, *resty.Response, error) {
+	path := fmt.Sprintf("/users/%d", id)
+	c.beforeMutate(ctx, path)
+
+	var result models.User
+	resp, err := c.newRequest(ctx).
+		SetBody(user).
+		SetResult(&result).
+		Put(path)
+	return &result, resp, CheckResponse(ctx, resp, err)
+}
+
+// PatchUser 部分更新用户
+func (c *APIClient) PatchUser(id int, user models.UpdateUserRequest) (*models.User, *resty.Response, error) {
+	return c.PatchUserWithContext(context.Background(), id, user)
+}
+
+// PatchUserWithContext 部分更新用户，请求受 ctx 控制
+func (c *APIClient) PatchUserWithContext(ctx context.Context, id int, user models.UpdateUserRequest) (*models.User, *resty.Response, error) {
+	path := fmt.Sprintf("/users/%d", id)
+	c.beforeMutate(ctx, path)
+
+	var result models.User
+	resp, err := c.newRequest(ctx).
+		SetBody(user).
+		SetResult(&result).
+		Patch(path)
+	return &result, resp, CheckResponse(ctx, resp, err)
+}
+
+// DeleteUser 删除用户
+func (c *APIClient) DeleteUser(id int) (*models.User, *resty.Response, error) {
+	return c.DeleteUserWithContext(context.Background(), id)
+}
+
+// DeleteUserWithContext 删除用户，请求受 ctx 控制
+func (c *APIClient) DeleteUserWithContext(ctx context.Context, id int) (*models.User, *resty.Response, error) {
+	path := fmt.Sprintf("/users/%d", id)
+	c.beforeMutate(ctx, path)
+
+	var result models.User
+	resp, err := c.newRequest(ctx).
+		SetResult(&result).
+		Delete(path)
+	if err = CheckResponse(ctx, resp, err); err == nil {
+		c.trackDeleted(path)
+	}
+	return &result, resp, err
 }
 
 // Login 用户登录
 func (c *APIClient) Login(loginReq models.LoginRequest) (*models.LoginResponse, *resty.Response, error) {
+	return c.LoginWithContext(context.Background(), loginReq)
+}
+
+// LoginWithContext 用户登录，请求受 ctx 控制
+func (c *APIClient) LoginWithContext(ctx context.Context, loginReq models.LoginRequest) (*models.LoginResponse, *resty.Response, error) {
 	var loginResp models.LoginResponse
-	resp, err := c.client.R().
+	resp, err := c.newRequest(ctx).
 		SetBody(loginReq).
 		SetResult(&loginResp).
 		Post("/auth/login")
-	return &loginResp, resp, err
-}
\ No newline at end of file
+	return &loginResp, resp, CheckResponse(ctx, resp, err)
+}
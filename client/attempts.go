@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Attempt 一次请求尝试的结果
+type Attempt struct {
+	Number      int           // 第几次尝试，从1开始
+	StatusCode  int           // 响应状态码，未收到响应时为0
+	Err         error         // 未收到响应或响应处理失败时的错误
+	Duration    time.Duration // 本次尝试的耗时，不含限速等待
+	Wait        time.Duration // 重试前的等待时间，没有重试时为0
+	LimiterWait time.Duration // 发送前等待限速器的时间
+	Drift       *DriftReport  // 严格解码模式下响应与模型的差异，没有差异时为nil
+}
+
+// attemptsKey 请求 context 中保存尝试记录的键
+type attemptsKey struct{}
+
+// attemptLog 一个请求的全部尝试，同一请求的尝试依次执行，锁只用于读取时的可见性
+type attemptLog struct {
+	mu          sync.Mutex
+	attempts    []Attempt
+	limiterWait time.Duration // 当前尝试等待限速器的时间，记录尝试后清零
+	drift       *DriftReport  // 当前尝试的模型差异，记录尝试后清零
+}
+
+// Attempts 返回 resp 对应请求的每次尝试，按顺序排列；不是由 APIClient 发出的请求返回nil
+func Attempts(resp *resty.Response) []Attempt {
+	if resp == nil || resp.Request == nil {
+		return nil
+	}
+	log, ok := resp.Request.Context().Value(attemptsKey{}).(*attemptLog)
+	if !ok {
+		return nil
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return append([]Attempt(nil), log.attempts...)
+}
+
+// withAttemptLog 返回可记录请求尝试的 context
+func withAttemptLog(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptsKey{}, &attemptLog{})
+}
+
+// recordAttempt 记录一次请求尝试
+func recordAttempt(e Exchange) {
+	log, ok := e.Request.Context().Value(attemptsKey{}).(*attemptLog)
+	if !ok {
+		return
+	}
+	attempt := Attempt{Number: e.Request.Attempt, Err: e.Err, Duration: e.Duration(), LimiterWait: e.LimiterWait, Drift: e.Drift}
+	if e.Response != nil {
+		attempt.StatusCode = e.Response.StatusCode()
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.attempts = append(log.attempts, attempt)
+	log.limiterWait = 0
+	log.drift = nil
+}
+
+// addLimiterWait 累加当前尝试等待限速器的时间，重定向时一次尝试会多次等待
+func addLimiterWait(ctx context.Context, wait time.Duration) {
+	log, ok := ctx.Value(attemptsKey{}).(*attemptLog)
+	if !ok || wait <= 0 {
+		return
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.limiterWait += wait
+}
+
+// limiterWait 返回当前尝试等待限速器的时间
+func limiterWait(req *resty.Request) time.Duration {
+	log, ok := req.Context().Value(attemptsKey{}).(*attemptLog)
+	if !ok {
+		return 0
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return log.limiterWait
+}
+
+// recordDrift 记录当前尝试的模型差异
+func recordDrift(req *resty.Request, report *DriftReport) {
+	log, ok := req.Context().Value(attemptsKey{}).(*attemptLog)
+	if !ok {
+		return
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.drift = report
+}
+
+// driftReport 返回当前尝试的模型差异
+func driftReport(req *resty.Request) *DriftReport {
+	log, ok := req.Context().Value(attemptsKey{}).(*attemptLog)
+	if !ok {
+		return nil
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return log.drift
+}
+
+// recordWait 记录最近一次尝试之后的等待时间
+func recordWait(req *resty.Request, wait time.Duration) {
+	log, ok := req.Context().Value(attemptsKey{}).(*attemptLog)
+	if !ok {
+		return
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if n := len(log.attempts); n > 0 {
+		log.attempts[n-1].Wait = wait
+	}
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-testify-allure-api-test/config"
+	"go-testify-allure-api-test/logging"
+)
+
+// ErrTargetUnavailable 熔断器打开期间的请求直接返回该错误，不会发送到目标服务
+var ErrTargetUnavailable = errors.New("目标服务不可用")
+
+// notSentKey context 中 *atomic.Bool 的键，breakerTransport 放入请求，MarkNotSent 设置
+type notSentKey struct{}
+
+// MarkNotSent 标记请求没有发送到目标服务、结果由传输层自己构造（如注入的故障、磁带回放），
+// 熔断器不把这次的结果计入目标服务的统计。供不经过网络直接返回结果的 http.RoundTripper 调用
+func MarkNotSent(req *http.Request) {
+	if notSent, ok := req.Context().Value(notSentKey{}).(*atomic.Bool); ok {
+		notSent.Store(true)
+	}
+}
+
+// CircuitState 熔断器状态
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // 正常放行请求
+	CircuitOpen     CircuitState = "open"      // 直接拒绝请求
+	CircuitHalfOpen CircuitState = "half-open" // 冷却结束，放行一个探测请求
+)
+
+// Breaker 按主机统计连续的网络错误和5xx响应，达到阈值后打开熔断器，
+// 冷却时间结束后放行一个探测请求：成功则关闭，失败则重新打开。可在多个客户端和 goroutine 之间共享
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	logger    *slog.Logger
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit 单个主机的熔断状态
+type circuit struct {
+	state    CircuitState
+	failures int       // 连续失败次数
+	openedAt time.Time // 最近一次打开的时间
+	probing  bool      // 半开状态下探测请求是否正在进行
+}
+
+// NewBreaker 创建连续失败 threshold 次后打开、cooldown 后半开的熔断器
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		logger:    logging.Default(),
+		circuits:  make(map[string]*circuit),
+	}
+}
+
+var (
+	sharedBreaker     *Breaker
+	sharedBreakerOnce sync.Once
+)
+
+// SharedBreaker 返回按全局 circuit_breaker 配置创建的熔断器，进程内所有客户端默认共享；
+// circuit_breaker.enabled 为 false 时返回nil
+func SharedBreaker() *Breaker {
+	sharedBreakerOnce.Do(func() {
+		cfg := config.GetConfig()
+		if cfg.CircuitBreaker.Enabled {
+			sharedBreaker = NewBreaker(cfg.CircuitBreaker.FailureThreshold, cfg.CircuitBreaker.Cooldown)
+		}
+	})
+	return sharedBreaker
+}
+
+// State 返回主机当前的熔断状态，冷却结束的打开状态报告为半开
+func (b *Breaker) State(host string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[host]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// allow 判断是否放行发往 host 的请求，拒绝时返回包装了 ErrTargetUnavailable 的错误
+func (b *Breaker) allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[host]
+	if !ok {
+		return nil
+	}
+
+	switch c.state {
+	case CircuitOpen:
+		retryAt := c.openedAt.Add(b.cooldown)
+		if time.Now().Before(retryAt) {
+			return fmt.Errorf("%w: %s 连续失败 %d 次，熔断至 %s", ErrTargetUnavailable, host, c.failures, retryAt.Format(time.TimeOnly))
+		}
+		b.transition(host, c, CircuitHalfOpen)
+		c.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if c.probing {
+			return fmt.Errorf("%w: %s 正在探测是否恢复", ErrTargetUnavailable, host)
+		}
+		c.probing = true
+	}
+	return nil
+}
+
+// outcome 一次请求的结果对熔断器的意义
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota // 清零连续失败次数，不是关闭状态时关闭
+	outcomeFailure                // 连续失败次数加一，达到阈值或半开时打开
+	outcomeIgnored                // 不计入统计，如请求被取消、结果不是目标服务返回的
+)
+
+// record 记录一次请求的结果
+func (b *Breaker) record(host string, result outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[host]
+	if !ok {
+		c = &circuit{state: CircuitClosed}
+		b.circuits[host] = c
+	}
+	if c.state == CircuitHalfOpen {
+		c.probing = false
+	}
+
+	switch result {
+	case outcomeFailure:
+		c.failures++
+		if c.state == CircuitHalfOpen || c.failures >= b.threshold {
+			c.openedAt = time.Now()
+			b.transition(host, c, CircuitOpen)
+		}
+	case outcomeSuccess:
+		c.failures = 0
+		if c.state != CircuitClosed {
+			b.transition(host, c, CircuitClosed)
+		}
+	}
+}
+
+// transition 切换状态并记录日志，调用方持有锁
+func (b *Breaker) transition(host string, c *circuit, state CircuitState) {
+	if c.state == state {
+		return
+	}
+	c.state = state
+	b.logger.LogAttrs(context.Background(), slog.LevelWarn, "circuit breaker "+string(state),
+		slog.String("host", host),
+		slog.Int("failures", c.failures),
+		slog.Duration("cooldown", b.cooldown),
+	)
+}
+
+// breakerTransport 熔断器打开时直接拒绝请求，否则发送请求并记录结果。
+// 下层传输层通过 MarkNotSent 标记的结果不计入统计
+type breakerTransport struct {
+	next    http.RoundTripper
+	breaker *Breaker
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if err := t.breaker.allow(host); err != nil {
+		return nil, err
+	}
+
+	notSent := &atomic.Bool{}
+	resp, err := t.next.RoundTrip(req.WithContext(context.WithValue(req.Context(), notSentKey{}, notSent)))
+	switch {
+	case notSent.Load():
+		// 注入的故障和回放的响应不代表目标服务的状态，计入统计会让并行的其他用例被熔断
+		t.breaker.record(host, outcomeIgnored)
+	case err != nil:
+		// 取消或超出 context 截止时间是调用方的决定，不代表目标服务不可用
+		if req.Context().Err() != nil {
+			t.breaker.record(host, outcomeIgnored)
+		} else {
+			t.breaker.record(host, outcomeFailure)
+		}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		t.breaker.record(host, outcomeFailure)
+	default:
+		t.breaker.record(host, outcomeSuccess)
+	}
+	return resp, err
+}
+
+// applyBreaker 用 breakerTransport 包装当前的传输层。在限速之后调用，熔断时不必等待限速器
+func (c *APIClient) applyBreaker() {
+	if c.breaker == nil {
+		return
+	}
+	next := c.client.GetClient().Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c.client.SetTransport(&breakerTransport{next: next, breaker: c.breaker})
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// CleanupStatus 单个资源的清理结果状态
+type CleanupStatus string
+
+const (
+	CleanupDeleted  CleanupStatus = "deleted"  // 删除了测试中创建的资源
+	CleanupRestored CleanupStatus = "restored" // 用修改前的快照恢复了已有资源
+	CleanupSkipped  CleanupStatus = "skipped"  // 无法通过 API 撤销
+	CleanupFailed   CleanupStatus = "failed"   // 撤销请求失败
+)
+
+// errDeletedResource 已有资源被删除后无法通过 API 重新创建为同一ID
+var errDeletedResource = errors.New("已删除的已有资源无法通过 API 恢复")
+
+// CleanupResult 单个资源的清理结果
+type CleanupResult struct {
+	Action string        // 执行的操作，如 "DELETE /products/21"
+	Status CleanupStatus // 结果状态
+	Err    error         // 失败或跳过的原因
+}
+
+// Cleanup 登记测试期间通过 APIClient 创建或修改的资源，测试结束时按相反顺序撤销：
+// 测试中创建的资源发送 DELETE，修改过的已有资源用第一次修改前的快照 PUT 回去。
+// 可在多个客户端和 goroutine 之间共享
+type Cleanup struct {
+	mu      sync.Mutex
+	entries []*cleanupEntry
+}
+
+// cleanupEntry 同一资源路径只登记一次，记录撤销所需的信息
+type cleanupEntry struct {
+	client   *APIClient
+	path     string
+	created  bool   // 测试中创建，清理时删除
+	original []byte // 已有资源修改前的响应体
+	deleted  bool   // 已有资源已被删除
+}
+
+// NewCleanup 创建空的清理登记表
+func NewCleanup() *Cleanup {
+	return &Cleanup{}
+}
+
+// Len 返回待清理的资源数
+func (r *Cleanup) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Run 按登记的相反顺序撤销所有资源并清空登记表，单个资源失败不影响其他资源
+func (r *Cleanup) Run(ctx context.Context) []CleanupResult {
+	r.mu.Lock()
+	entries := r.entries
+	r.entries = nil
+	r.mu.Unlock()
+
+	results := make([]CleanupResult, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		results = append(results, entries[i].undo(ctx))
+	}
+	return results
+}
+
+// find 返回资源路径对应的登记项，调用方需持有锁
+func (r *Cleanup) find(path string) (int, *cleanupEntry) {
+	for i, e := range r.entries {
+		if e.path == path {
+			return i, e
+		}
+	}
+	return -1, nil
+}
+
+// undo 撤销单个资源
+func (e *cleanupEntry) undo(ctx context.Context) CleanupResult {
+	switch {
+	case e.created:
+		resp, err := e.client.newRequest(ctx).Delete(e.path)
+		return newCleanupResult("DELETE "+e.path, CleanupDeleted, CheckResponse(ctx, resp, err))
+	case e.deleted:
+		return CleanupResult{Action: "RESTORE " + e.path, Status: CleanupSkipped, Err: errDeletedResource}
+	default:
+		resp, err := e.client.newRequest(ctx).SetBody(e.original).Put(e.path)
+		return newCleanupResult("PUT "+e.path, CleanupRestored, CheckResponse(ctx, resp, err))
+	}
+}
+
+// newCleanupResult 根据撤销请求的错误决定结果状态
+func newCleanupResult(action string, status CleanupStatus, err error) CleanupResult {
+	if err != nil {
+		return CleanupResult{Action: action, Status: CleanupFailed, Err: err}
+	}
+	return CleanupResult{Action: action, Status: status}
+}
+
+// trackCreated 登记测试中创建的资源
+func (c *APIClient) trackCreated(path string) {
+	if c.cleanup == nil {
+		return
+	}
+	c.cleanup.mu.Lock()
+	defer c.cleanup.mu.Unlock()
+	if _, e := c.cleanup.find(path); e == nil {
+		c.cleanup.entries = append(c.cleanup.entries, &cleanupEntry{client: c, path: path, created: true})
+	}
+}
+
+// beforeMutate 在修改或删除资源前保存快照，同一资源只保存第一次修改前的状态。
+// 资源不存在或获取失败时不登记
+func (c *APIClient) beforeMutate(ctx context.Context, path string) {
+	if c.cleanup == nil {
+		return
+	}
+	c.cleanup.mu.Lock()
+	_, e := c.cleanup.find(path)
+	c.cleanup.mu.Unlock()
+	if e != nil {
+		return
+	}
+
+	resp, err := c.newRequest(ctx).Get(path)
+	if err != nil || !resp.IsSuccess() {
+		return
+	}
+
+	c.cleanup.mu.Lock()
+	defer c.cleanup.mu.Unlock()
+	if _, e := c.cleanup.find(path); e == nil {
+		c.cleanup.entries = append(c.cleanup.entries, &cleanupEntry{client: c, path: path, original: resp.Body()})
+	}
+}
+
+// trackDeleted 记录资源已被删除：测试中创建的资源无需再清理，已有资源标记为无法恢复
+func (c *APIClient) trackDeleted(path string) {
+	if c.cleanup == nil {
+		return
+	}
+	c.cleanup.mu.Lock()
+	defer c.cleanup.mu.Unlock()
+	i, e := c.cleanup.find(path)
+	switch {
+	case e == nil:
+	case e.created:
+		c.cleanup.entries = append(c.cleanup.entries[:i], c.cleanup.entries[i+1:]...)
+	default:
+		e.deleted = true
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// CurlCommand 返回复现该请求的 curl 命令，所有参数都经过 shell 转义，敏感请求头的值显示为 ******，
+// 请求体原样保留。请求发送后调用时使用实际请求的地址和请求头
+func CurlCommand(req *resty.Request) string {
+	url := req.URL
+	header := req.Header
+	if raw := req.RawRequest; raw != nil {
+		url = raw.URL.String()
+		header = raw.Header
+	}
+	header = RedactHeaders(header)
+
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{"curl", "-X", req.Method, ShellQuote(url)}
+	for _, name := range names {
+		for _, value := range header[name] {
+			parts = append(parts, "-H", ShellQuote(name+": "+value))
+		}
+	}
+	if body, ok := RequestBody(req); ok {
+		parts = append(parts, "--data-raw", ShellQuote(body))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Curl 返回复现响应对应请求的 curl 命令，resp 为nil时返回空字符串
+func Curl(resp *resty.Response) string {
+	if resp == nil || resp.Request == nil {
+		return ""
+	}
+	return CurlCommand(resp.Request)
+}
+
+// RequestBody 按 resty 的规则把请求体序列化为字符串：[]byte 和 string 原样发送，其他类型编码为 JSON。
+// 没有请求体时第二个返回值为 false
+func RequestBody(req *resty.Request) (string, bool) {
+	switch v := req.Body.(type) {
+	case nil:
+		return "", false
+	case []byte:
+		return string(v), true
+	case string:
+		return v, true
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%+v", v), true
+		}
+		return string(data), true
+	}
+}
+
+// ShellQuote 用单引号包裹参数，参数中的单引号转义为 '\''
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go-testify-allure-api-test/schema"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// StrictMode 严格解码模式，对应 api.strict_decoding
+type StrictMode string
+
+const (
+	StrictOff    StrictMode = "off"    // 不检查
+	StrictReport StrictMode = "report" // 记录差异报告，不影响请求结果
+	StrictFail   StrictMode = "fail"   // 记录差异报告，并返回 *DriftError
+)
+
+// 差异类型
+const (
+	DriftUnknown = "unknown" // 响应中有、模型中没有的字段，DisallowUnknownFields 会拒绝
+	DriftMissing = "missing" // 模型要求、响应中缺少的字段
+)
+
+// Drift 响应与模型之间的一处差异
+type Drift struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"` // JSON 路径，数组元素记为 [*]，如 $[*].rating.count
+}
+
+// DriftReport 一个响应与解码目标模型之间的全部差异
+type DriftReport struct {
+	Method string  `json:"method"`
+	URL    string  `json:"url"`
+	Model  string  `json:"model"` // 解码目标的类型，如 []models.Product
+	Drifts []Drift `json:"drifts"`
+}
+
+// Paths 返回指定类型差异的路径
+func (r *DriftReport) Paths(kind string) []string {
+	var paths []string
+	for _, d := range r.Drifts {
+		if d.Kind == kind {
+			paths = append(paths, d.Path)
+		}
+	}
+	return paths
+}
+
+// String 返回一行概要，如 "GET /products → []models.Product: unknown $[*].brand; missing $[*].rating"
+func (r *DriftReport) String() string {
+	var parts []string
+	for _, kind := range []string{DriftUnknown, DriftMissing} {
+		if paths := r.Paths(kind); len(paths) > 0 {
+			parts = append(parts, kind+" "+strings.Join(paths, ", "))
+		}
+	}
+	return fmt.Sprintf("%s %s → %s: %s", r.Method, r.URL, r.Model, strings.Join(parts, "; "))
+}
+
+// DriftError 严格解码模式为 fail 时响应与模型不一致的错误，可通过 errors.As 获取
+type DriftError struct {
+	Report *DriftReport
+}
+
+// Error 实现 error 接口
+func (e *DriftError) Error() string {
+	return "响应与模型不一致: " + e.Report.String()
+}
+
+// CheckDrift 比较 JSON 响应体与 target 的类型，返回未知字段和缺少的必填字段，按路径排序。
+// 必填字段与 JSON Schema 一样由 jsonschema 标签中的 required 决定（见 schema.IsRequired）；字段名与 encoding/json 一样不区分大小写；
+// 实现了 json.Unmarshaler 的类型（如 time.Time）不再深入比较。body 不是合法 JSON 时返回nil
+func CheckDrift(body []byte, target interface{}) []Drift {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+
+	seen := make(map[Drift]bool)
+	var drifts []Drift
+	walkDrift(value, reflect.TypeOf(target), "$", func(d Drift) {
+		if !seen[d] {
+			seen[d] = true
+			drifts = append(drifts, d)
+		}
+	})
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Path != drifts[j].Path {
+			return drifts[i].Path < drifts[j].Path
+		}
+		return drifts[i].Kind < drifts[j].Kind
+	})
+	return drifts
+}
+
+// unmarshalerType json.Unmarshaler 接口类型
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// walkDrift 同时遍历 JSON 值和 Go 类型，把差异交给 report
+func walkDrift(value interface{}, t reflect.Type, path string, report func(Drift)) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || value == nil || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for key, v := range object {
+			f, ok := matchField(fields, key)
+			if !ok {
+				report(Drift{Kind: DriftUnknown, Path: path + "." + key})
+				continue
+			}
+			walkDrift(v, f.typ, path+"."+f.name, report)
+		}
+		for _, f := range fields {
+			if f.required && !hasKey(object, f.name) {
+				report(Drift{Kind: DriftMissing, Path: path + "." + f.name})
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for _, item := range items {
+			walkDrift(item, t.Elem(), path+"[*]", report)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, v := range object {
+			walkDrift(v, t.Elem(), path+"[*]", report)
+		}
+	}
+}
+
+// jsonField 结构体中参与 JSON 解码的字段
+type jsonField struct {
+	name     string
+	typ      reflect.Type
+	required bool
+}
+
+// jsonFields 按 json 标签返回结构体的字段，忽略未导出字段和标签为 "-" 的字段
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, jsonField{
+			name:     name,
+			typ:      sf.Type,
+			required: schema.IsRequired(sf),
+		})
+	}
+	return fields
+}
+
+// matchField 按 encoding/json 的规则查找字段：优先完全匹配，否则不区分大小写
+func matchField(fields []jsonField, key string) (jsonField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// hasKey 判断对象中是否有与 name 匹配的键
+func hasKey(object map[string]interface{}, name string) bool {
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// registerDrift 在严格解码模式下比较2xx响应体与 SetResult 的目标类型，
+// 差异报告记录在本次尝试中，由 Hook 和 Attempts 读取；模式为 fail 时返回 *DriftError
+func (c *APIClient) registerDrift() {
+	c.client.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		if c.drift == StrictOff || c.drift == "" || !resp.IsSuccess() || resp.Request.Result == nil {
+			return nil
+		}
+		drifts := CheckDrift(resp.Body(), resp.Request.Result)
+		if len(drifts) == 0 {
+			return nil
+		}
+
+		report := &DriftReport{
+			Method: resp.Request.Method,
+			URL:    requestPath(resp.Request),
+			Model:  strings.TrimPrefix(reflect.TypeOf(resp.Request.Result).String(), "*"),
+			Drifts: drifts,
+		}
+		recordDrift(resp.Request, report)
+		c.logger.LogAttrs(resp.Request.Context(), slog.LevelWarn, "schema drift",
+			slog.String("method", report.Method),
+			slog.String("path", report.URL),
+			slog.String("model", report.Model),
+			slog.Any("unknown", report.Paths(DriftUnknown)),
+			slog.Any("missing", report.Paths(DriftMissing)),
+			slog.String("request_id", resp.Request.Header.Get(requestIDHeader)),
+			slog.String("test", c.testName),
+		)
+		if c.drift == StrictFail {
+			return &DriftError{Report: report}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-testify-allure-api-test/models"
+
+	"github.com/go-resty/resty/v2"
+)
+
+var (
+	// ErrRequestCanceled 请求因 context 被取消而中止
+	ErrRequestCanceled = errors.New("请求已取消")
+	// ErrRequestTimeout 请求因 context 截止时间到达而中止
+	ErrRequestTimeout = errors.New("请求超过截止时间")
+)
+
+// checkContext 将 context 导致的失败包装为可区分的错误，
+// 返回的错误同时满足 errors.Is(err, ErrRequestCanceled) 与 errors.Is(err, context.Canceled)
+func checkContext(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrRequestTimeout, ctx.Err())
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("%w: %w", ErrRequestCanceled, ctx.Err())
+	}
+	return err
+}
+
+// APIError 非2xx响应对应的错误，可通过 errors.As 获取
+type APIError struct {
+	StatusCode int                   // HTTP状态码
+	Method     string                // 请求方法
+	URL        string                // 请求地址
+	RequestID  string                // 请求ID，对应 X-Request-ID 请求头
+	Response   *models.ErrorResponse // 解码后的错误响应，响应体不是JSON时为nil
+	Body       string                // 原始响应体
+	Curl       string                // 复现请求的 curl 命令，敏感请求头已隐藏
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	message := e.Body
+	if e.Response != nil && e.Response.Message != "" {
+		message = e.Response.Message
+	}
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s 返回 %d (request_id=%s): %s", e.Method, e.URL, e.StatusCode, e.RequestID, message)
+}
+
+// newAPIError 根据非2xx响应构造 APIError，成功响应返回nil
+func newAPIError(resp *resty.Response) error {
+	if resp == nil || resp.IsSuccess() {
+		return nil
+	}
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode(),
+		Method:     resp.Request.Method,
+		URL:        resp.Request.URL,
+		RequestID:  resp.Request.Header.Get(requestIDHeader),
+		Body:       string(resp.Body()),
+		Curl:       CurlCommand(resp.Request),
+	}
+	var errResp models.ErrorResponse
+	if err := json.Unmarshal(resp.Body(), &errResp); err == nil && (errResp.Message != "" || errResp.Code != 0) {
+		apiErr.Response = &errResp
+	}
+	return apiErr
+}
+
+// CheckResponse 统一处理请求错误：先识别 context 错误，再将非2xx响应转换为 APIError。
+// fakestore.Client 等其他 StoreAPI 实现同样通过它构造错误，各实现返回的错误一致
+func CheckResponse(ctx context.Context, resp *resty.Response, err error) error {
+	if err != nil {
+		return checkContext(ctx, err)
+	}
+	return newAPIError(resp)
+}
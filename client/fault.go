@@ -0,0 +1,307 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go-testify-allure-api-test/config"
+	"go-testify-allure-api-test/logging"
+)
+
+// faultHeader 被注入故障的响应携带的响应头，值为故障类型，便于在附件和 HAR 中识别
+const faultHeader = "X-Fault-Injected"
+
+// FaultKind 故障类型
+type FaultKind string
+
+const (
+	FaultLatency   FaultKind = "latency"   // 正常发送请求，收到响应后延迟 Latency 再返回
+	FaultReset     FaultKind = "reset"     // 不发送请求，返回连接被重置的错误
+	FaultTimeout   FaultKind = "timeout"   // 不发送请求，直到请求超时或等待 Latency 后返回超时错误
+	FaultTruncate  FaultKind = "truncate"  // 响应体读到一半时返回 io.ErrUnexpectedEOF
+	FaultMalformed FaultKind = "malformed" // 响应体只返回前一半，不是完整的 JSON
+	FaultStatus    FaultKind = "status"    // 不发送请求，直接返回 Status 状态码
+)
+
+// FaultRule 故障注入规则，Method 和 Path 都匹配的请求按 Probability 注入故障
+type FaultRule struct {
+	Method      string        // 请求方法，空或 * 匹配所有方法
+	Path        string        // 路径模式，语法同 path.Match，空匹配所有路径
+	Fault       FaultKind     // 故障类型
+	Status      int           // Fault 为 FaultStatus 时返回的状态码
+	Latency     time.Duration // 注入的延迟；Fault 为 FaultTimeout 时为等待上限，0表示等到请求超时
+	RetryAfter  time.Duration // Fault 为 FaultStatus 时响应的 Retry-After，0表示不设置
+	Probability float64       // 匹配的请求中注入故障的比例，0-1，0与1相同，表示每次都注入
+	Times       int           // 最多注入的次数，0表示不限制
+}
+
+// matches 判断请求是否匹配规则
+func (r FaultRule) matches(req *http.Request) bool {
+	if r.Method != "" && r.Method != "*" && !strings.EqualFold(r.Method, req.Method) {
+		return false
+	}
+	if r.Path == "" {
+		return true
+	}
+	ok, _ := path.Match(r.Path, req.URL.Path)
+	return ok
+}
+
+// FaultInjector 按规则决定哪些请求注入故障，可在测试过程中增删规则。
+// 多条规则匹配同一请求时使用第一条仍可注入的规则
+type FaultInjector struct {
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	rules    []*faultState
+	injected int
+}
+
+// faultState 规则及其已注入的次数
+type faultState struct {
+	rule     FaultRule
+	injected int
+}
+
+// NewFaultInjector 创建按 rules 注入故障的 FaultInjector
+func NewFaultInjector(rules ...FaultRule) *FaultInjector {
+	f := &FaultInjector{logger: logging.Default()}
+	f.Add(rules...)
+	return f
+}
+
+// NewFaultInjectorFromConfig 根据 fault_injection 配置创建 FaultInjector，
+// 未启用或没有规则时返回nil；无效的规则（配置校验已报告）和 probability 为0、从不注入的规则被忽略
+func NewFaultInjectorFromConfig(cfg *config.Config) *FaultInjector {
+	if !cfg.FaultInjection.Enabled {
+		return nil
+	}
+	rules, _ := cfg.FaultRules()
+	if len(rules) == 0 {
+		return nil
+	}
+	f := NewFaultInjector()
+	for _, r := range rules {
+		// 配置中省略 probability 时为1，0表示从不注入；FaultRule 的0表示每次都注入
+		if r.Probability == 0 {
+			continue
+		}
+		f.Add(FaultRule{
+			Method:      r.Method,
+			Path:        r.Path,
+			Fault:       FaultKind(r.Fault),
+			Status:      r.Status,
+			Latency:     r.Latency,
+			RetryAfter:  r.RetryAfter,
+			Probability: r.Probability,
+			Times:       r.Times,
+		})
+	}
+	if len(f.rules) == 0 {
+		return nil
+	}
+	return f
+}
+
+// Add 追加规则，对之后发出的请求生效
+func (f *FaultInjector) Add(rules ...FaultRule) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range rules {
+		f.rules = append(f.rules, &faultState{rule: r})
+	}
+}
+
+// Clear 删除所有规则，之后的请求不再注入故障
+func (f *FaultInjector) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = nil
+}
+
+// Injected 返回已注入故障的次数
+func (f *FaultInjector) Injected() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.injected
+}
+
+// pick 返回请求要注入的故障，不注入时 ok 为 false
+func (f *FaultInjector) pick(req *http.Request) (rule FaultRule, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, s := range f.rules {
+		if !s.rule.matches(req) || (s.rule.Times > 0 && s.injected >= s.rule.Times) {
+			continue
+		}
+		if p := s.rule.Probability; p > 0 && p < 1 && rand.Float64() >= p {
+			continue
+		}
+		s.injected++
+		f.injected++
+		return s.rule, true
+	}
+	return FaultRule{}, false
+}
+
+// faultTransport 按 FaultInjector 的规则注入故障，不注入时直接发送请求
+type faultTransport struct {
+	next     http.RoundTripper
+	injector *FaultInjector
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rule, ok := t.injector.pick(req)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.String("fault", string(rule.Fault)),
+		slog.String("request_id", req.Header.Get(requestIDHeader)),
+	}
+	if rule.Fault == FaultStatus {
+		attrs = append(attrs, slog.Int("status", rule.Status))
+	}
+	t.injector.logger.LogAttrs(ctx, slog.LevelWarn, "fault injected", attrs...)
+
+	switch rule.Fault {
+	case FaultTimeout, FaultReset, FaultStatus:
+		// 不经过网络，按复用连接触发 httptrace 回调，使 resp.Time() 包含注入的延迟；结果不计入熔断器
+		TraceReusedConn(req)
+		MarkNotSent(req)
+		if rule.Fault == FaultTimeout {
+			if err := sleep(ctx, rule.Latency); err != nil {
+				return nil, err
+			}
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}
+		}
+		if rule.Latency > 0 {
+			if err := sleep(ctx, rule.Latency); err != nil {
+				return nil, err
+			}
+		}
+		if rule.Fault == FaultReset {
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+		}
+		return statusResponse(req, rule), nil
+	}
+
+	// 其余故障需要真实的响应，延迟加在收到响应之后，与响应慢的服务一致
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Latency > 0 {
+		if err := sleep(ctx, rule.Latency); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+	if rule.Fault == FaultTruncate || rule.Fault == FaultMalformed {
+		return corruptBody(resp, rule.Fault)
+	}
+	resp.Header.Set(faultHeader, string(rule.Fault))
+	return resp, nil
+}
+
+// sleep 等待 d，d 为0时一直等到 ctx 结束；ctx 先结束时返回 ctx.Err()
+func sleep(ctx context.Context, d time.Duration) error {
+	var timeout <-chan time.Time
+	if d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-timeout:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// statusResponse 构造状态码为 rule.Status 的 JSON 错误响应
+func statusResponse(req *http.Request, rule FaultRule) *http.Response {
+	body := fmt.Sprintf(`{"status":%d,"message":%q}`, rule.Status, http.StatusText(rule.Status))
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(faultHeader, string(rule.Fault))
+	if rule.RetryAfter > 0 {
+		header.Set("Retry-After", strconv.Itoa(int(math.Ceil(rule.RetryAfter.Seconds()))))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rule.Status, http.StatusText(rule.Status)),
+		StatusCode:    rule.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// corruptBody 读取完整的响应体后只保留前一半。truncate 在读完这一半后返回 io.ErrUnexpectedEOF，
+// Content-Length 保持原值；malformed 正常结束，Content-Length 改为截断后的长度
+func corruptBody(resp *http.Response, kind FaultKind) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	half := body[:len(body)/2]
+
+	resp.Header.Set(faultHeader, string(kind))
+	if kind == FaultTruncate {
+		resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(half), errReader{io.ErrUnexpectedEOF}))
+		return resp, nil
+	}
+	if len(half) == 0 {
+		half = []byte("{")
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(half))
+	resp.ContentLength = int64(len(half))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(half)))
+	return resp, nil
+}
+
+// errReader 每次读取都返回 err
+type errReader struct{ err error }
+
+// Read 实现 io.Reader
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// applyFaults 用 faultTransport 包装当前的传输层。在限速和熔断之前调用，
+// 不发送请求的故障（status、reset、timeout）不计入熔断器，注入的延迟不计入限速等待
+func (c *APIClient) applyFaults() {
+	if c.faults == nil {
+		return
+	}
+	next := c.client.GetClient().Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c.client.SetTransport(&faultTransport{next: next, injector: c.faults})
+}
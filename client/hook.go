@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Exchange 一次请求尝试及其结果。重试时每次尝试各对应一个 Exchange
+type Exchange struct {
+	Request  *resty.Request
+	Response *resty.Response // 网络错误等未收到响应时为nil
+	Err      error           // 未收到响应或响应处理失败时的错误
+
+	LimiterWait time.Duration // 发送前等待限速器的时间，不计入 Duration
+	Drift       *DriftReport  // 严格解码模式下响应与模型的差异，没有差异时为nil
+}
+
+// Duration 返回本次尝试的耗时，不含等待限速器的时间
+func (e Exchange) Duration() time.Duration {
+	if e.Response != nil {
+		return e.Response.Time()
+	}
+	if e.Request.Time.IsZero() {
+		return 0
+	}
+	return time.Since(e.Request.Time) - e.LimiterWait
+}
+
+// Hook 每次请求尝试完成后调用，用于把请求和响应写入报告等。
+// 同一客户端的请求可能并发，Hook 需要自行保证并发安全
+type Hook func(ctx context.Context, e Exchange)
+
+// registerHooks 在每次收到响应和最终失败时调用已注册的 Hook
+func (c *APIClient) registerHooks() {
+	c.client.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		c.runHooks(Exchange{Request: resp.Request, Response: resp})
+		return nil
+	})
+
+	// 网络错误后的重试不会经过 OnAfterResponse，在重试前补上这次尝试。
+	// 最后一次尝试由 OnError 处理，这里跳过以免重复
+	c.client.AddRetryHook(func(resp *resty.Response, err error) {
+		if err == nil || resp == nil || resp.RawResponse != nil || resp.Request.Attempt > c.client.RetryCount {
+			return
+		}
+		c.runHooks(Exchange{Request: resp.Request, Err: err})
+	})
+
+	// 收到响应后处理失败时 OnAfterResponse 不会执行，这里补上；网络错误时没有响应
+	c.client.OnError(func(req *resty.Request, err error) {
+		e := Exchange{Request: req, Err: err}
+		var respErr *resty.ResponseError
+		if errors.As(err, &respErr) {
+			e.Err = respErr.Err
+			if respErr.Response.RawResponse != nil {
+				e.Response = respErr.Response
+			}
+		}
+		c.runHooks(e)
+	})
+}
+
+// runHooks 记录本次尝试并依次调用所有 Hook
+func (c *APIClient) runHooks(e Exchange) {
+	e.LimiterWait = limiterWait(e.Request)
+	e.Drift = driftReport(e.Request)
+	recordAttempt(e)
+	for _, hook := range c.hooks {
+		hook(e.Request.Context(), e)
+	}
+}
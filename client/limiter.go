@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-testify-allure-api-test/config"
+)
+
+// Limit 一个主机的限速规则
+type Limit struct {
+	RPS         float64 // 每秒允许的请求数，0表示不限速
+	Burst       int     // 令牌桶容量，即允许的瞬时请求数
+	MaxInFlight int     // 同时进行的请求数上限，0表示不限制
+}
+
+// Limiter 按主机限制请求速率和同时进行的请求数。
+// 每个主机有独立的令牌桶和信号量，可在多个客户端和 goroutine 之间共享
+type Limiter struct {
+	defaults Limit
+	hosts    map[string]Limit
+
+	mu    sync.Mutex
+	state map[string]*hostLimiter
+}
+
+// NewLimiter 创建限速器，hosts 中的主机使用各自的规则，其他主机使用 defaults
+func NewLimiter(defaults Limit, hosts map[string]Limit) *Limiter {
+	return &Limiter{defaults: defaults, hosts: hosts, state: make(map[string]*hostLimiter)}
+}
+
+var (
+	sharedLimiter     *Limiter
+	sharedLimiterOnce sync.Once
+)
+
+// SharedLimiter 返回按全局 rate_limit 配置创建的限速器，进程内所有客户端默认共享
+func SharedLimiter() *Limiter {
+	sharedLimiterOnce.Do(func() {
+		sharedLimiter = NewLimiterFromConfig(config.GetConfig())
+	})
+	return sharedLimiter
+}
+
+// NewLimiterFromConfig 根据 rate_limit 配置创建限速器，rate_limit.hosts 无效的项被忽略（配置校验已报告）
+func NewLimiterFromConfig(cfg *config.Config) *Limiter {
+	hosts := make(map[string]Limit)
+	limits, _ := cfg.HostLimits()
+	for _, l := range limits {
+		hosts[l.Host] = Limit{RPS: l.RPS, Burst: l.Burst, MaxInFlight: l.MaxInFlight}
+	}
+	return NewLimiter(Limit{
+		RPS:         cfg.RateLimit.RPS,
+		Burst:       cfg.RateLimit.Burst,
+		MaxInFlight: cfg.RateLimit.MaxInFlight,
+	}, hosts)
+}
+
+// Wait 等待 host 的令牌和请求名额，返回释放名额的函数和等待的时间。
+// host 可带端口，先按完整的 host 查找规则，再按不带端口的主机名查找。ctx 结束时返回 ctx.Err()
+func (l *Limiter) Wait(ctx context.Context, host string) (release func(), waited time.Duration, err error) {
+	h := l.host(host)
+	if h == nil {
+		return func() {}, 0, nil
+	}
+
+	start := time.Now()
+	if err := h.take(ctx); err != nil {
+		return nil, time.Since(start), err
+	}
+	if h.sem != nil {
+		select {
+		case h.sem <- struct{}{}:
+		case <-ctx.Done():
+			// 请求没有发出，归还已取出的令牌，否则共享的限速器会永久少一个令牌
+			h.refund()
+			return nil, time.Since(start), ctx.Err()
+		}
+	}
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			if h.sem != nil {
+				<-h.sem
+			}
+		})
+	}
+	return release, time.Since(start), nil
+}
+
+// host 返回主机的限速状态，不限速的主机返回nil
+func (l *Limiter) host(host string) *hostLimiter {
+	limit, key := l.defaults, host
+	if hostLimit, ok := l.hosts[host]; ok {
+		limit = hostLimit
+	} else if name := hostname(host); name != host {
+		if hostLimit, ok := l.hosts[name]; ok {
+			limit, key = hostLimit, name
+		}
+	}
+	if limit.RPS <= 0 && limit.MaxInFlight <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h, ok := l.state[key]
+	if !ok {
+		h = newHostLimiter(limit)
+		l.state[key] = h
+	}
+	return h
+}
+
+// hostname 去掉 host 中的端口
+func hostname(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return host
+}
+
+// hostLimiter 单个主机的令牌桶和信号量
+type hostLimiter struct {
+	rate  float64
+	burst float64
+	sem   chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newHostLimiter 创建装满令牌的 hostLimiter
+func newHostLimiter(limit Limit) *hostLimiter {
+	h := &hostLimiter{rate: limit.RPS, burst: float64(limit.Burst), tokens: float64(limit.Burst), last: time.Now()}
+	if limit.MaxInFlight > 0 {
+		h.sem = make(chan struct{}, limit.MaxInFlight)
+	}
+	return h
+}
+
+// take 取出一个令牌，令牌不足时预支并等待到令牌补足；ctx 结束时归还预支的令牌
+func (h *hostLimiter) take(ctx context.Context) error {
+	if h.rate <= 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	now := time.Now()
+	h.tokens += now.Sub(h.last).Seconds() * h.rate
+	if h.tokens > h.burst {
+		h.tokens = h.burst
+	}
+	h.last = now
+	h.tokens--
+	var wait time.Duration
+	if h.tokens < 0 {
+		wait = time.Duration(-h.tokens / h.rate * float64(time.Second))
+	}
+	h.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		h.refund()
+		return ctx.Err()
+	}
+}
+
+// refund 归还 take 取出的令牌
+func (h *hostLimiter) refund() {
+	if h.rate <= 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens++
+}
+
+// limitedTransport 发送请求前等待限速器，响应体关闭后释放请求名额。
+// 等待时间记录在请求的尝试记录中，由日志、Hook 和 Attempts 读取
+type limitedTransport struct {
+	next    http.RoundTripper
+	limiter *Limiter
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, waited, err := t.limiter.Wait(req.Context(), req.URL.Host)
+	addLimiterWait(req.Context(), waited)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseOnClose 在响应体关闭时释放请求名额
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close 关闭响应体并释放请求名额
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// applyLimiter 用 limitedTransport 包装当前的传输层，在所有选项之后调用，使 WithTransport 设置的传输层同样受限
+func (c *APIClient) applyLimiter() {
+	if c.limiter == nil {
+		return
+	}
+	next := c.client.GetClient().Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c.client.SetTransport(&limitedTransport{next: next, limiter: c.limiter})
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// registerLogging 为每次请求、每次响应和最终失败记录结构化日志。
+// 重试时每次尝试都会单独记录，attempt 字段从1开始；等待过限速器时记录 limiter_wait
+func (c *APIClient) registerLogging() {
+	c.client.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+		c.logger.LogAttrs(req.Context(), slog.LevelDebug, "http request",
+			slog.String("method", req.Method),
+			slog.String("path", requestPath(req)),
+			slog.Int("attempt", req.Attempt),
+			slog.String("request_id", req.Header.Get(requestIDHeader)),
+			slog.String("test", c.testName),
+		)
+		return nil
+	})
+
+	c.client.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		level := slog.LevelInfo
+		if resp.StatusCode() >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", resp.Request.Method),
+			slog.String("path", requestPath(resp.Request)),
+			slog.Int("status", resp.StatusCode()),
+			slog.Duration("duration", resp.Time()),
+			slog.Int("attempt", resp.Request.Attempt),
+			slog.Int64("size", resp.Size()),
+			slog.String("request_id", resp.Request.Header.Get(requestIDHeader)),
+			slog.String("test", c.testName),
+		}
+		if wait := limiterWait(resp.Request); wait > 0 {
+			attrs = append(attrs, slog.Duration("limiter_wait", wait))
+		}
+		c.logger.LogAttrs(resp.Request.Context(), level, "http response", attrs...)
+		return nil
+	})
+
+	c.client.OnError(func(req *resty.Request, err error) {
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("path", requestPath(req)),
+			slog.Int("attempt", req.Attempt),
+			slog.String("request_id", req.Header.Get(requestIDHeader)),
+			slog.String("test", c.testName),
+			slog.String("error", err.Error()),
+		}
+		if !req.Time.IsZero() {
+			attrs = append(attrs, slog.Duration("duration", time.Since(req.Time)))
+		}
+		if wait := limiterWait(req); wait > 0 {
+			attrs = append(attrs, slog.Duration("limiter_wait", wait))
+		}
+		c.logger.LogAttrs(req.Context(), slog.LevelError, "http request failed", attrs...)
+	})
+}
+
+// requestPath 返回请求路径。发送前 URL 仍是相对路径，发送后取实际请求的路径
+func requestPath(req *resty.Request) string {
+	if req.RawRequest != nil && req.RawRequest.URL != nil {
+		return req.RawRequest.URL.Path
+	}
+	return req.URL
+}
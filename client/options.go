@@ -0,0 +1,118 @@
+package client
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Option APIClient 的可选配置
+type Option func(*APIClient)
+
+// WithBaseURL 覆盖基础地址
+func WithBaseURL(baseURL string) Option {
+	return func(c *APIClient) {
+		c.client.SetBaseURL(baseURL)
+		c.baseURL = baseURL
+	}
+}
+
+// WithTimeout 覆盖单次请求的超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *APIClient) {
+		c.client.SetTimeout(timeout)
+	}
+}
+
+// WithRetry 覆盖重试次数和重试等待时间，重试条件仍使用配置中的 retry 策略
+func WithRetry(count int, waitTime, maxWaitTime time.Duration) Option {
+	return func(c *APIClient) {
+		c.retry.Count = count
+		c.retry.WaitTime = waitTime
+		c.retry.MaxWaitTime = maxWaitTime
+	}
+}
+
+// WithRetryPolicy 使用指定的重试策略代替按配置创建的策略
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *APIClient) {
+		c.retry = policy
+	}
+}
+
+// WithHeader 为所有请求设置请求头
+func WithHeader(key, value string) Option {
+	return func(c *APIClient) {
+		c.client.SetHeader(key, value)
+	}
+}
+
+// WithHeaders 为所有请求设置多个请求头
+func WithHeaders(headers map[string]string) Option {
+	return func(c *APIClient) {
+		c.client.SetHeaders(headers)
+	}
+}
+
+// WithLogger 使用指定的日志记录器代替按全局配置创建的默认记录器
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *APIClient) {
+		c.logger = logger
+	}
+}
+
+// WithTestName 在该客户端的每条请求日志中记录测试名称
+func WithTestName(name string) Option {
+	return func(c *APIClient) {
+		c.testName = name
+	}
+}
+
+// WithCleanup 把该客户端创建、修改和删除的资源登记到 registry，测试结束时由 registry.Run 撤销
+func WithCleanup(registry *Cleanup) Option {
+	return func(c *APIClient) {
+		c.cleanup = registry
+	}
+}
+
+// WithHook 在每次请求尝试完成后调用 hook，可多次使用注册多个 Hook
+func WithHook(hook Hook) Option {
+	return func(c *APIClient) {
+		c.hooks = append(c.hooks, hook)
+	}
+}
+
+// WithTransport 使用指定的 http.RoundTripper 发送请求，例如录制和回放请求的 vcr.Transport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *APIClient) {
+		c.client.SetTransport(transport)
+	}
+}
+
+// WithLimiter 使用指定的限速器代替进程内共享的限速器，为nil时不限速
+func WithLimiter(limiter *Limiter) Option {
+	return func(c *APIClient) {
+		c.limiter = limiter
+	}
+}
+
+// WithBreaker 使用指定的熔断器代替进程内共享的熔断器，为nil时不熔断
+func WithBreaker(breaker *Breaker) Option {
+	return func(c *APIClient) {
+		c.breaker = breaker
+	}
+}
+
+// WithFaults 使用指定的 FaultInjector 代替按 fault_injection 配置创建的规则，为nil时不注入故障
+func WithFaults(injector *FaultInjector) Option {
+	return func(c *APIClient) {
+		c.faults = injector
+	}
+}
+
+// WithStrictDecoding 使用指定的严格解码模式代替 api.strict_decoding 配置
+func WithStrictDecoding(mode StrictMode) Option {
+	return func(c *APIClient) {
+		c.drift = mode
+	}
+}
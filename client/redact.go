@@ -0,0 +1,165 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// redactedValue 敏感请求头和请求体字段在日志和报告中显示的值
+const redactedValue = "******"
+
+// sensitiveHeaders 值需要隐藏的请求头和响应头
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// sensitiveFields 值需要隐藏的 JSON 字段，比较时忽略大小写
+var sensitiveFields = []string{
+	"password",
+	"token",
+	"access_token",
+	"refresh_token",
+	"secret",
+	"client_secret",
+	"api_key",
+}
+
+// IsSensitiveHeader 判断请求头的值是否需要隐藏
+func IsSensitiveHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	for _, h := range sensitiveHeaders {
+		if h == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaders 返回隐藏了敏感值的请求头副本，不修改传入的 header
+func RedactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name, values := range redacted {
+		if IsSensitiveHeader(name) {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+	return redacted
+}
+
+// IsSensitiveField 判断 JSON 字段的值是否需要隐藏
+func IsSensitiveField(name string) bool {
+	for _, f := range sensitiveFields {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactBody 返回隐藏了敏感字段值的请求体，任意层级的对象中的敏感字段都会被替换，其余字段保持原有顺序。
+// 不是 JSON 或没有敏感字段时原样返回，有敏感字段时重新编码为紧凑的 JSON
+func RedactBody(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var out bytes.Buffer
+	redacted, err := redactJSON(decoder, &out)
+	if err != nil || !redacted {
+		return body
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		// JSON 之后还有其他内容，不是单个 JSON 文档
+		return body
+	}
+	return out.Bytes()
+}
+
+// redactJSON 从 decoder 读取一个 JSON 值并写入 out，敏感字段的值替换为 ******，返回是否有字段被替换
+func redactJSON(decoder *json.Decoder, out *bytes.Buffer) (bool, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return false, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return false, writeToken(out, token)
+	}
+
+	redacted := false
+	out.WriteRune(rune(delim))
+	for i := 0; decoder.More(); i++ {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		if delim == '{' {
+			key, err := decoder.Token()
+			if err != nil {
+				return false, err
+			}
+			if err := writeToken(out, key); err != nil {
+				return false, err
+			}
+			out.WriteByte(':')
+			if name, _ := key.(string); IsSensitiveField(name) {
+				if err := skipJSON(decoder); err != nil {
+					return false, err
+				}
+				writeToken(out, redactedValue)
+				redacted = true
+				continue
+			}
+		}
+		r, err := redactJSON(decoder, out)
+		if err != nil {
+			return false, err
+		}
+		redacted = redacted || r
+	}
+	end, err := decoder.Token()
+	if err != nil {
+		return false, err
+	}
+	out.WriteRune(rune(end.(json.Delim)))
+	return redacted, nil
+}
+
+// skipJSON 从 decoder 读取并丢弃一个 JSON 值
+func skipJSON(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); ok {
+			if delim == '{' || delim == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// writeToken 把字符串、数字、布尔值或 null 编码为 JSON 写入 out，不转义 HTML 字符
+func writeToken(out *bytes.Buffer, token json.Token) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(token); err != nil {
+		return err
+	}
+	// Encode 在末尾追加换行
+	out.Truncate(out.Len() - 1)
+	return nil
+}
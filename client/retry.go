@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-testify-allure-api-test/config"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// RetryPolicy 决定哪些请求尝试需要重试以及重试前等待多久
+type RetryPolicy struct {
+	Count          int           // 最大重试次数，0表示不重试
+	Statuses       []int         // 需要重试的响应状态码
+	NetworkErrors  bool          // 未收到响应的网络错误是否重试
+	WaitTime       time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxWaitTime    time.Duration // 单次等待时间上限，Retry-After 也不超过该值
+	Jitter         float64       // 随机减少等待时间的最大比例，0-1
+	RetryAfter     bool          // 按响应的 Retry-After 头等待
+	ExcludeMethods []string      // 不重试的请求方法
+}
+
+// NewRetryPolicy 根据 api.retry_count 和 retry 配置创建重试策略
+func NewRetryPolicy(cfg *config.Config) RetryPolicy {
+	return RetryPolicy{
+		Count:          cfg.API.RetryCount,
+		Statuses:       cfg.Retry.Statuses,
+		NetworkErrors:  cfg.Retry.NetworkErrors,
+		WaitTime:       cfg.Retry.WaitTime,
+		MaxWaitTime:    cfg.Retry.MaxWaitTime,
+		Jitter:         cfg.Retry.Jitter,
+		RetryAfter:     cfg.Retry.RetryAfter,
+		ExcludeMethods: cfg.Retry.ExcludeMethods,
+	}
+}
+
+// ShouldRetry 判断一次请求尝试是否需要重试，不考虑剩余的重试次数。
+// 已取消或超时的请求、被熔断器拒绝的请求、ExcludeMethods 中的方法都不重试
+func (p RetryPolicy) ShouldRetry(method string, statusCode int, err error) bool {
+	for _, m := range p.ExcludeMethods {
+		if strings.EqualFold(m, method) {
+			return false
+		}
+	}
+	if statusCode == 0 {
+		return err != nil && p.NetworkErrors && !errors.Is(err, ErrTargetUnavailable) &&
+			!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	for _, status := range p.Statuses {
+		if status == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay 返回第 attempt 次尝试（从1开始）之后的等待时间：WaitTime 按次数翻倍，不超过 MaxWaitTime，
+// 再随机减少最多 Jitter 比例。响应带有 Retry-After 且 RetryAfter 为 true 时按该值等待，同样不超过 MaxWaitTime
+func (p RetryPolicy) Delay(attempt int, header http.Header) time.Duration {
+	if p.RetryAfter && header != nil {
+		if wait, ok := parseRetryAfter(header.Get("Retry-After"), time.Now()); ok {
+			return minDuration(wait, p.MaxWaitTime)
+		}
+	}
+
+	wait := float64(p.WaitTime) * math.Exp2(float64(attempt-1))
+	wait = math.Min(wait, float64(p.MaxWaitTime))
+	wait -= wait * p.Jitter * rand.Float64()
+	return time.Duration(wait)
+}
+
+// parseRetryAfter 解析秒数或 HTTP 日期格式的 Retry-After
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// minDuration 返回较小的时间
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// registerRetry 按 c.retry 决定是否重试以及等待时间，并为每次重试记录日志。
+// resty 只在等待前调用 RetryAfter，最后一次尝试不会记录等待时间
+func (c *APIClient) registerRetry() {
+	c.client.AddRetryCondition(func(resp *resty.Response, err error) bool {
+		if resp == nil || resp.Request == nil {
+			return false
+		}
+		status := 0
+		if resp.RawResponse != nil {
+			status = resp.StatusCode()
+		}
+		return c.retry.ShouldRetry(resp.Request.Method, status, err)
+	})
+
+	c.client.SetRetryAfter(func(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+		var header http.Header
+		if resp.RawResponse != nil {
+			header = resp.Header()
+		}
+		wait := c.retry.Delay(resp.Request.Attempt, header)
+		recordWait(resp.Request, wait)
+
+		attrs := []slog.Attr{
+			slog.String("method", resp.Request.Method),
+			slog.String("path", requestPath(resp.Request)),
+			slog.Int("attempt", resp.Request.Attempt),
+			slog.Duration("wait", wait),
+			slog.String("request_id", resp.Request.Header.Get(requestIDHeader)),
+			slog.String("test", c.testName),
+		}
+		if resp.RawResponse != nil {
+			attrs = append(attrs, slog.Int("status", resp.StatusCode()))
+		}
+		c.logger.LogAttrs(resp.Request.Context(), slog.LevelWarn, "http retry", attrs...)
+
+		// resty 把0当作使用默认算法，用最小的正数代替
+		if wait <= 0 {
+			wait = time.Nanosecond
+		}
+		return wait, nil
+	})
+}
+
+// applyRetry 把重试次数和等待上限同步到 resty 客户端。等待时间由 RetryAfter 计算，
+// resty 会把结果限制在 [RetryWaitTime, RetryMaxWaitTime] 内，因此下限设为0
+func (c *APIClient) applyRetry() {
+	c.client.SetRetryCount(c.retry.Count)
+	c.client.SetRetryWaitTime(0)
+	c.client.SetRetryMaxWaitTime(c.retry.MaxWaitTime)
+}
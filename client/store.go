@@ -0,0 +1,261 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"go-testify-allure-api-test/models"
+)
+
+// Products 商品接口。
+// 方法只返回解码后的模型和错误：非2xx响应返回 *APIError，context 导致的失败返回 ErrRequestCanceled 或 ErrRequestTimeout
+type Products interface {
+	GetAllProducts(ctx context.Context) ([]models.Product, error)
+	GetProductByID(ctx context.Context, id int) (*models.Product, error)
+	GetProductsByLimit(ctx context.Context, limit int) ([]models.Product, error)
+	GetProductsBySort(ctx context.Context, sort string) ([]models.Product, error)
+	CreateProduct(ctx context.Context, product models.CreateProductRequest) (*models.Product, error)
+	UpdateProduct(ctx context.Context, id int, product models.UpdateProductRequest) (*models.Product, error)
+	PatchProduct(ctx context.Context, id int, product models.UpdateProductRequest) (*models.Product, error)
+	DeleteProduct(ctx context.Context, id int) (*models.Product, error)
+}
+
+// Categories 商品分类接口
+type Categories interface {
+	GetAllCategories(ctx context.Context) ([]string, error)
+	GetProductsByCategory(ctx context.Context, category string) ([]models.Product, error)
+}
+
+// Carts 购物车接口
+type Carts interface {
+	GetAllCarts(ctx context.Context) ([]models.Cart, error)
+	GetCartByID(ctx context.Context, id int) (*models.Cart, error)
+	GetCartsByLimit(ctx context.Context, limit int) ([]models.Cart, error)
+	GetCartsBySort(ctx context.Context, sort string) ([]models.Cart, error)
+	GetCartsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]models.Cart, error)
+	GetCartsByUserID(ctx context.Context, userID int) ([]models.Cart, error)
+	CreateCart(ctx context.Context, cart models.CreateCartRequest) (*models.Cart, error)
+	UpdateCart(ctx context.Context, id int, cart models.UpdateCartRequest) (*models.Cart, error)
+	PatchCart(ctx context.Context, id int, cart models.UpdateCartRequest) (*models.Cart, error)
+	DeleteCart(ctx context.Context, id int) (*models.Cart, error)
+}
+
+// Users 用户接口
+type Users interface {
+	GetAllUsers(ctx context.Context) ([]models.User, error)
+	GetUserByID(ctx context.Context, id int) (*models.User, error)
+	GetUsersByLimit(ctx context.Context, limit int) ([]models.User, error)
+	GetUsersBySort(ctx context.Context, sort string) ([]models.User, error)
+	AddUser(ctx context.Context, user models.CreateUserRequest) (*models.User, error)
+	UpdateUser(ctx context.Context, id int, user models.UpdateUserRequest) (*models.User, error)
+	PatchUser(ctx context.Context, id int, user models.UpdateUserRequest) (*models.User, error)
+	DeleteUser(ctx context.Context, id int) (*models.User, error)
+}
+
+// Auth 认证接口
+type Auth interface {
+	Login(ctx context.Context, loginReq models.LoginRequest) (*models.LoginResponse, error)
+	SetAuthToken(token string)
+}
+
+// StoreAPI Fake Store API 的全部接口，不暴露 HTTP 客户端的细节。辅助函数依赖 StoreAPI 或其中某个资源接口，
+// 而不是 *APIClient，即可在单元测试中换成 fakestore.Client 等内存实现
+type StoreAPI interface {
+	Products
+	Categories
+	Carts
+	Users
+	Auth
+}
+
+// StoreAPI 返回基于 c 的 StoreAPI 实现，每个方法调用 c 对应的 ...WithContext 方法，只返回结果和错误
+func (c *APIClient) StoreAPI() StoreAPI {
+	return storeAPI{c: c}
+}
+
+// storeAPI 把 APIClient 适配为 StoreAPI
+type storeAPI struct {
+	c *APIClient
+}
+
+// storeAPI 实现 StoreAPI，方法签名变化时编译失败
+var _ StoreAPI = storeAPI{}
+
+// GetAllProducts 调用 APIClient.GetAllProductsWithContext
+func (s storeAPI) GetAllProducts(ctx context.Context) ([]models.Product, error) {
+	result, _, err := s.c.GetAllProductsWithContext(ctx)
+	return result, err
+}
+
+// GetProductByID 调用 APIClient.GetProductByIDWithContext
+func (s storeAPI) GetProductByID(ctx context.Context, id int) (*models.Product, error) {
+	result, _, err := s.c.GetProductByIDWithContext(ctx, id)
+	return result, err
+}
+
+// GetProductsByLimit 调用 APIClient.GetProductsByLimitWithContext
+func (s storeAPI) GetProductsByLimit(ctx context.Context, limit int) ([]models.Product, error) {
+	result, _, err := s.c.GetProductsByLimitWithContext(ctx, limit)
+	return result, err
+}
+
+// GetProductsBySort 调用 APIClient.GetProductsBySortWithContext
+func (s storeAPI) GetProductsBySort(ctx context.Context, sort string) ([]models.Product, error) {
+	result, _, err := s.c.GetProductsBySortWithContext(ctx, sort)
+	return result, err
+}
+
+// CreateProduct 调用 APIClient.CreateProductWithContext
+func (s storeAPI) CreateProduct(ctx context.Context, product models.CreateProductRequest) (*models.Product, error) {
+	result, _, err := s.c.CreateProductWithContext(ctx, product)
+	return result, err
+}
+
+// UpdateProduct 调用 APIClient.UpdateProductWithContext
+func (s storeAPI) UpdateProduct(ctx context.Context, id int, product models.UpdateProductRequest) (*models.Product, error) {
+	result, _, err := s.c.UpdateProductWithContext(ctx, id, product)
+	return result, err
+}
+
+// PatchProduct 调用 APIClient.PatchProductWithContext
+func (s storeAPI) PatchProduct(ctx context.Context, id int, product models.UpdateProductRequest) (*models.Product, error) {
+	result, _, err := s.c.PatchProductWithContext(ctx, id, product)
+	return result, err
+}
+
+// DeleteProduct 调用 APIClient.DeleteProductWithContext
+func (s storeAPI) DeleteProduct(ctx context.Context, id int) (*models.Product, error) {
+	result, _, err := s.c.DeleteProductWithContext(ctx, id)
+	return result, err
+}
+
+// GetAllCategories 调用 APIClient.GetAllCategoriesWithContext
+func (s storeAPI) GetAllCategories(ctx context.Context) ([]string, error) {
+	result, _, err := s.c.GetAllCategoriesWithContext(ctx)
+	return result, err
+}
+
+// GetProductsByCategory 调用 APIClient.GetProductsByCategoryWithContext
+func (s storeAPI) GetProductsByCategory(ctx context.Context, category string) ([]models.Product, error) {
+	result, _, err := s.c.GetProductsByCategoryWithContext(ctx, category)
+	return result, err
+}
+
+// GetAllCarts 调用 APIClient.GetAllCartsWithContext
+func (s storeAPI) GetAllCarts(ctx context.Context) ([]models.Cart, error) {
+	result, _, err := s.c.GetAllCartsWithContext(ctx)
+	return result, err
+}
+
+// GetCartByID 调用 APIClient.GetCartByIDWithContext
+func (s storeAPI) GetCartByID(ctx context.Context, id int) (*models.Cart, error) {
+	result, _, err := s.c.GetCartByIDWithContext(ctx, id)
+	return result, err
+}
+
+// GetCartsByLimit 调用 APIClient.GetCartsByLimitWithContext
+func (s storeAPI) GetCartsByLimit(ctx context.Context, limit int) ([]models.Cart, error) {
+	result, _, err := s.c.GetCartsByLimitWithContext(ctx, limit)
+	return result, err
+}
+
+// GetCartsBySort 调用 APIClient.GetCartsBySortWithContext
+func (s storeAPI) GetCartsBySort(ctx context.Context, sort string) ([]models.Cart, error) {
+	result, _, err := s.c.GetCartsBySortWithContext(ctx, sort)
+	return result, err
+}
+
+// GetCartsByDateRange 调用 APIClient.GetCartsByDateRangeWithContext
+func (s storeAPI) GetCartsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]models.Cart, error) {
+	result, _, err := s.c.GetCartsByDateRangeWithContext(ctx, startDate, endDate)
+	return result, err
+}
+
+// GetCartsByUserID 调用 APIClient.GetCartsByUserIDWithContext
+func (s storeAPI) GetCartsByUserID(ctx context.Context, userID int) ([]models.Cart, error) {
+	result, _, err := s.c.GetCartsByUserIDWithContext(ctx, userID)
+	return result, err
+}
+
+// CreateCart 调用 APIClient.CreateCartWithContext
+func (s storeAPI) CreateCart(ctx context.Context, cart models.CreateCartRequest) (*models.Cart, error) {
+	result, _, err := s.c.CreateCartWithContext(ctx, cart)
+	return result, err
+}
+
+// UpdateCart 调用 APIClient.UpdateCartWithContext
+func (s storeAPI) UpdateCart(ctx context.Context, id int, cart models.UpdateCartRequest) (*models.Cart, error) {
+	result, _, err := s.c.UpdateCartWithContext(ctx, id, cart)
+	return result, err
+}
+
+// PatchCart 调用 APIClient.PatchCartWithContext
+func (s storeAPI) PatchCart(ctx context.Context, id int, cart models.UpdateCartRequest) (*models.Cart, error) {
+	result, _, err := s.c.PatchCartWithContext(ctx, id, cart)
+	return result, err
+}
+
+// DeleteCart 调用 APIClient.DeleteCartWithContext
+func (s storeAPI) DeleteCart(ctx context.Context, id int) (*models.Cart, error) {
+	result, _, err := s.c.DeleteCartWithContext(ctx, id)
+	return result, err
+}
+
+// GetAllUsers 调用 APIClient.GetAllUsersWithContext
+func (s storeAPI) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	result, _, err := s.c.GetAllUsersWithContext(ctx)
+	return result, err
+}
+
+// GetUserByID 调用 APIClient.GetUserByIDWithContext
+func (s storeAPI) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	result, _, err := s.c.GetUserByIDWithContext(ctx, id)
+	return result, err
+}
+
+// GetUsersByLimit 调用 APIClient.GetUsersByLimitWithContext
+func (s storeAPI) GetUsersByLimit(ctx context.Context, limit int) ([]models.User, error) {
+	result, _, err := s.c.GetUsersByLimitWithContext(ctx, limit)
+	return result, err
+}
+
+// GetUsersBySort 调用 APIClient.GetUsersBySortWithContext
+func (s storeAPI) GetUsersBySort(ctx context.Context, sort string) ([]models.User, error) {
+	result, _, err := s.c.GetUsersBySortWithContext(ctx, sort)
+	return result, err
+}
+
+// AddUser 调用 APIClient.AddUserWithContext
+func (s storeAPI) AddUser(ctx context.Context, user models.CreateUserRequest) (*models.User, error) {
+	result, _, err := s.c.AddUserWithContext(ctx, user)
+	return result, err
+}
+
+// UpdateUser 调用 APIClient.UpdateUserWithContext
+func (s storeAPI) UpdateUser(ctx context.Context, id int, user models.UpdateUserRequest) (*models.User, error) {
+	result, _, err := s.c.UpdateUserWithContext(ctx, id, user)
+	return result, err
+}
+
+// PatchUser 调用 APIClient.PatchUserWithContext
+func (s storeAPI) PatchUser(ctx context.Context, id int, user models.UpdateUserRequest) (*models.User, error) {
+	result, _, err := s.c.PatchUserWithContext(ctx, id, user)
+	return result, err
+}
+
+// DeleteUser 调用 APIClient.DeleteUserWithContext
+func (s storeAPI) DeleteUser(ctx context.Context, id int) (*models.User, error) {
+	result, _, err := s.c.DeleteUserWithContext(ctx, id)
+	return result, err
+}
+
+// Login 调用 APIClient.LoginWithContext
+func (s storeAPI) Login(ctx context.Context, loginReq models.LoginRequest) (*models.LoginResponse, error) {
+	result, _, err := s.c.LoginWithContext(ctx, loginReq)
+	return result, err
+}
+
+// SetAuthToken 调用 APIClient.SetAuthToken
+func (s storeAPI) SetAuthToken(token string) {
+	s.c.SetAuthToken(token)
+}
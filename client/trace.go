@@ -0,0 +1,24 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptrace"
+)
+
+// TraceReusedConn 按复用连接触发请求上的 httptrace 回调，供不经过网络直接构造响应的 http.RoundTripper 使用。
+// 不触发时 resty 按零值时间计算 resp.Time()，得到错误的耗时
+func TraceReusedConn(req *http.Request) {
+	trace := httptrace.ContextClientTrace(req.Context())
+	if trace == nil {
+		return
+	}
+	if trace.GetConn != nil {
+		trace.GetConn(req.URL.Host)
+	}
+	if trace.GotConn != nil {
+		trace.GotConn(httptrace.GotConnInfo{Reused: true})
+	}
+	if trace.GotFirstResponseByte != nil {
+		trace.GotFirstResponseByte()
+	}
+}
@@ -1,19 +1,68 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"sort"
+	"strings"
 	"sync"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
+	Environment string `mapstructure:"environment"`
+
 	API struct {
-		BaseURL    string `mapstructure:"base_url"`
-		Timeout    int    `mapstructure:"timeout"`
-		RetryCount int    `mapstructure:"retry_count"`
+		BaseURL        string `mapstructure:"base_url"`
+		Timeout        int    `mapstructure:"timeout"`
+		RetryCount     int    `mapstructure:"retry_count"`
+		StrictDecoding string `mapstructure:"strict_decoding"`
 	} `mapstructure:"api"`
 
+	Retry struct {
+		Statuses       []int         `mapstructure:"statuses"`
+		NetworkErrors  bool          `mapstructure:"network_errors"`
+		WaitTime       time.Duration `mapstructure:"wait_time"`
+		MaxWaitTime    time.Duration `mapstructure:"max_wait_time"`
+		Jitter         float64       `mapstructure:"jitter"`
+		RetryAfter     bool          `mapstructure:"retry_after"`
+		ExcludeMethods []string      `mapstructure:"exclude_methods"`
+	} `mapstructure:"retry"`
+
+	RateLimit struct {
+		RPS         float64  `mapstructure:"rps"`
+		Burst       int      `mapstructure:"burst"`
+		MaxInFlight int      `mapstructure:"max_in_flight"`
+		Hosts       []string `mapstructure:"hosts"`
+	} `mapstructure:"rate_limit"`
+
+	CircuitBreaker struct {
+		Enabled          bool          `mapstructure:"enabled"`
+		FailureThreshold int           `mapstructure:"failure_threshold"`
+		Cooldown         time.Duration `mapstructure:"cooldown"`
+	} `mapstructure:"circuit_breaker"`
+
+	FaultInjection struct {
+		Enabled bool     `mapstructure:"enabled"`
+		Rules   []string `mapstructure:"rules"`
+	} `mapstructure:"fault_injection"`
+
+	Auth struct {
+		Username string `mapstructure:"username"`
+		Password string `mapstructure:"password"`
+	} `mapstructure:"auth"`
+
+	SLA struct {
+		ListResponseTime time.Duration `mapstructure:"list_response_time"`
+		ItemResponseTime time.Duration `mapstructure:"item_response_time"`
+	} `mapstructure:"sla"`
+
 	Allure struct {
 		ResultsDir string `mapstructure:"results_dir"`
 		ReportDir  string `mapstructure:"report_dir"`
@@ -25,11 +74,129 @@ type Config struct {
 		Cleanup  bool `mapstructure:"cleanup"`
 	} `mapstructure:"test"`
 
+	Preflight struct {
+		Enabled     bool          `mapstructure:"enabled"`
+		OnFailure   string        `mapstructure:"on_failure"`
+		Endpoints   []string      `mapstructure:"endpoints"`
+		ContentType string        `mapstructure:"content_type"`
+		Timeout     time.Duration `mapstructure:"timeout"`
+	} `mapstructure:"preflight"`
+
+	HAR struct {
+		Enabled bool   `mapstructure:"enabled"`
+		Scope   string `mapstructure:"scope"`
+	} `mapstructure:"har"`
+
+	VCR struct {
+		Mode   string   `mapstructure:"mode"`
+		Dir    string   `mapstructure:"dir"`
+		Format string   `mapstructure:"format"`
+		Match  []string `mapstructure:"match"`
+	} `mapstructure:"vcr"`
+
+	OpenAPI struct {
+		Enabled bool   `mapstructure:"enabled"`
+		Spec    string `mapstructure:"spec"`
+		Mode    string `mapstructure:"mode"`
+	} `mapstructure:"openapi"`
+
 	Logging struct {
 		Level  string `mapstructure:"level"`
 		Format string `mapstructure:"format"`
 		Output string `mapstructure:"output"`
+		File   string `mapstructure:"file"`
 	} `mapstructure:"logging"`
+
+	settings []Setting
+}
+
+const (
+	// EnvPrefix 环境变量前缀，例如 api.base_url 对应 APITEST_API_BASE_URL
+	EnvPrefix = "APITEST"
+	// FlagPrefix 命令行参数前缀，例如 api.base_url 对应 -apitest.api.base_url，
+	// 避免 test.* 配置项与 go test 自带的 -test.* 参数冲突
+	FlagPrefix = "apitest."
+)
+
+// Source 配置值的来源
+type Source string
+
+const (
+	SourceDefault Source = "default" // 内置默认值
+	SourceFile    Source = "file"    // config.yaml
+	SourceProfile Source = "profile" // config.yaml 中选中的 environments.<name>
+	SourceEnv     Source = "env"     // 环境变量
+	SourceFlag    Source = "flag"    // 命令行参数
+)
+
+// Setting 生效的配置项及其来源
+type Setting struct {
+	Key    string
+	Value  interface{}
+	Source Source
+}
+
+// Overrides 配置覆盖来源。优先级从高到低依次为：
+// 命令行参数 > 环境变量 > 选中的环境配置 > 配置文件 > 默认值
+type Overrides struct {
+	ConfigFile string                           // 配置文件路径，为空时在 . 和 ./config 中查找 config.yaml
+	Env        func(name string) (string, bool) // 环境变量查找函数，为nil时使用 os.LookupEnv
+	Flags      map[string]string                // 命令行参数，键为配置项名称，如 api.base_url
+}
+
+// defaults 所有配置项及其默认值，同时决定可被覆盖的配置项
+var defaults = []struct {
+	key   string
+	value interface{}
+}{
+	{"environment", ""},
+	{"api.base_url", "https://fakestoreapi.com"},
+	{"api.timeout", 30},
+	{"api.retry_count", 3},
+	{"api.strict_decoding", "off"},
+	{"retry.statuses", []int{429, 500, 502, 503, 504}},
+	{"retry.network_errors", true},
+	{"retry.wait_time", 1 * time.Second},
+	{"retry.max_wait_time", 5 * time.Second},
+	{"retry.jitter", 0.2},
+	{"retry.retry_after", true},
+	{"retry.exclude_methods", []string{"POST", "PATCH"}},
+	{"rate_limit.rps", 0.0},
+	{"rate_limit.burst", 1},
+	{"rate_limit.max_in_flight", 0},
+	{"rate_limit.hosts", []string{}},
+	{"circuit_breaker.enabled", true},
+	{"circuit_breaker.failure_threshold", 5},
+	{"circuit_breaker.cooldown", 30 * time.Second},
+	{"fault_injection.enabled", false},
+	{"fault_injection.rules", []string{}},
+	{"auth.username", "mor_2314"},
+	{"auth.password", "83r5^_"},
+	{"sla.list_response_time", 5 * time.Second},
+	{"sla.item_response_time", 3 * time.Second},
+	{"allure.results_dir", "allure-results"},
+	{"allure.report_dir", "allure-report"},
+	{"test.parallel", true},
+	{"test.verbose", true},
+	{"test.cleanup", true},
+	{"preflight.enabled", true},
+	{"preflight.on_failure", "skip"},
+	{"preflight.endpoints", []string{"/products?limit=1"}},
+	{"preflight.content_type", "application/json"},
+	{"preflight.timeout", 10 * time.Second},
+	{"har.enabled", true},
+	{"har.scope", "test"},
+	{"vcr.mode", "passthrough"},
+	{"vcr.dir", "testdata/cassettes"},
+	{"vcr.format", "yaml"},
+	{"vcr.match", []string{"method", "path", "query"}},
+	{"openapi.enabled", false},
+	{"openapi.spec", "testdata/openapi.yaml"},
+	{"openapi.mode", "fail"},
+	{"logging.level", "info"},
+	{"logging.format", "json"},
+	{"logging.output", "console"},
+	{"logging.file", "logs/api-test.log"},
 }
 
 var (
@@ -45,34 +212,202 @@ func GetConfig() *Config {
 	return instance
 }
 
-// loadConfig 加载配置文件
+// loadConfig 加载配置文件，并应用环境变量和命令行参数覆盖。
+// 配置无法解析或校验失败时立即退出，避免针对错误的目标运行测试
 func loadConfig() *Config {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("./config")
+	config, err := Resolve(Overrides{Flags: commandLineFlags()})
+	if err != nil {
+		log.Fatalf("Unable to decode config: %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	return config
+}
+
+// Resolve 按优先级合并默认值、配置文件、环境变量和命令行参数，返回生效的配置
+func Resolve(o Overrides) (*Config, error) {
+	lookupEnv := o.Env
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	v := viper.New()
+	if o.ConfigFile != "" {
+		v.SetConfigFile(o.ConfigFile)
+	} else {
+		v.SetConfigName("config")
+		v.SetConfigType("yaml")
+		v.AddConfigPath(".")
+		v.AddConfigPath("./config")
+	}
 
 	// 设置默认值
-	viper.SetDefault("api.base_url", "https://fakestoreapi.com")
-	viper.SetDefault("api.timeout", 30)
-	viper.SetDefault("api.retry_count", 3)
-	viper.SetDefault("allure.results_dir", "allure-results")
-	viper.SetDefault("allure.report_dir", "allure-report")
-	viper.SetDefault("test.parallel", true)
-	viper.SetDefault("test.verbose", true)
-	viper.SetDefault("test.cleanup", true)
-	viper.SetDefault("logging.level", "info")
-	viper.SetDefault("logging.format", "json")
-	viper.SetDefault("logging.output", "console")
-
-	if err := viper.ReadInConfig(); err != nil {
+	for _, d := range defaults {
+		v.SetDefault(d.key, d.value)
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		// 只有在默认位置找不到配置文件时才回退到默认值，文件损坏或指定的文件不存在都直接报错
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
 		log.Printf("Warning: Could not read config file: %v. Using defaults.", err)
 	}
 
+	for key := range o.Flags {
+		if !isKnownKey(key) {
+			return nil, fmt.Errorf("unknown config key %q", key)
+		}
+	}
+
+	profile, err := selectProfile(v, o.Flags, lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings []Setting
+	for _, d := range defaults {
+		source := SourceDefault
+		if value, ok := o.Flags[d.key]; ok {
+			v.Set(d.key, value)
+			source = SourceFlag
+		} else if value, ok := lookupEnv(EnvName(d.key)); ok {
+			v.Set(d.key, value)
+			source = SourceEnv
+		} else if profile != nil && profile.IsSet(d.key) {
+			v.Set(d.key, profile.Get(d.key))
+			source = SourceProfile
+		} else if v.InConfig(d.key) {
+			source = SourceFile
+		}
+		settings = append(settings, Setting{Key: d.key, Source: source})
+	}
+
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
-		log.Fatalf("Unable to decode config: %v", err)
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, err
+	}
+
+	for i := range settings {
+		settings[i].Value = v.Get(settings[i].Key)
+	}
+	config.settings = settings
+
+	return &config, nil
+}
+
+// selectProfile 按 命令行参数 > 环境变量 > 配置文件 的顺序确定环境名称，并返回对应的环境配置。
+// 未选择环境时返回nil
+func selectProfile(v *viper.Viper, flags map[string]string, lookupEnv func(string) (string, bool)) (*viper.Viper, error) {
+	name := v.GetString("environment")
+	if value, ok := lookupEnv(EnvName("environment")); ok {
+		name = value
+	}
+	if value, ok := flags["environment"]; ok {
+		name = value
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	profile := v.Sub("environments." + name)
+	if profile == nil {
+		available := make([]string, 0)
+		for key := range v.GetStringMap("environments") {
+			available = append(available, key)
+		}
+		sort.Strings(available)
+		return nil, fmt.Errorf("unknown environment %q, available: %s", name, strings.Join(available, ", "))
+	}
+	for _, key := range profile.AllKeys() {
+		if key == "environment" || !isKnownKey(key) {
+			return nil, fmt.Errorf("environment %q: unknown config key %q", name, key)
+		}
+	}
+	return profile, nil
+}
+
+// ProfileName 返回选中的环境名称，未选择时返回 "default"
+func (c *Config) ProfileName() string {
+	if c.Environment == "" {
+		return "default"
+	}
+	return c.Environment
+}
+
+// EnvName 返回配置项对应的环境变量名
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// FlagName 返回配置项对应的命令行参数名
+func FlagName(key string) string {
+	return FlagPrefix + key
+}
+
+// RegisterFlags 为每个配置项注册命令行参数，例如 -apitest.api.base_url。
+// 导入本包不会注册任何参数，需要命令行覆盖的程序在解析参数前调用 RegisterFlags(flag.CommandLine)
+func RegisterFlags(fs *flag.FlagSet) {
+	for _, d := range defaults {
+		fs.String(FlagName(d.key), "", fmt.Sprintf("覆盖配置项 %s（环境变量 %s）", d.key, EnvName(d.key)))
+	}
+}
+
+// commandLineFlags 收集命令行中显式设置的配置项，参数尚未解析或未调用 RegisterFlags 时返回nil
+func commandLineFlags() map[string]string {
+	if !flag.Parsed() {
+		return nil
 	}
+	flags := make(map[string]string)
+	flag.Visit(func(f *flag.Flag) {
+		if key := strings.TrimPrefix(f.Name, FlagPrefix); key != f.Name && isKnownKey(key) {
+			flags[key] = f.Value.String()
+		}
+	})
+	return flags
+}
 
-	return &config
-}
\ No newline at end of file
+// isKnownKey 判断是否为已知配置项
+func isKnownKey(key string) bool {
+	for _, d := range defaults {
+		if d.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Settings 返回所有生效的配置项及其来源，按配置项名称排序
+func (c *Config) Settings() []Setting {
+	settings := append([]Setting(nil), c.settings...)
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+// Source 返回配置项的来源，未知配置项返回空字符串
+func (c *Config) Source(key string) Source {
+	for _, s := range c.settings {
+		if s.Key == key {
+			return s.Source
+		}
+	}
+	return ""
+}
+
+// Report 以表格形式描述生效的配置及其来源
+func (c *Config) Report() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, s := range c.Settings() {
+		value := s.Value
+		if strings.HasSuffix(s.Key, "password") && fmt.Sprint(value) != "" {
+			value = "******"
+		}
+		fmt.Fprintf(w, "%s\t%v\t%s\n", s.Key, value, s.Source)
+	}
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FaultRule 单条故障注入规则
+type FaultRule struct {
+	Method      string        // 请求方法，* 匹配所有方法
+	Path        string        // 路径模式，语法同 path.Match，如 /products/*
+	Fault       string        // 故障类型，latency、reset、timeout、truncate、malformed 或 status
+	Status      int           // fault 为 status 时返回的状态码
+	Latency     time.Duration // 注入的延迟；fault 为 timeout 时为等待上限，0表示等到请求超时
+	RetryAfter  time.Duration // fault 为 status 时响应的 Retry-After，0表示不设置
+	Probability float64       // 匹配的请求中注入故障的比例，0-1，省略时为1，0表示从不注入
+	Times       int           // 最多注入的次数，0表示不限制
+}
+
+// FaultRules 解析 fault_injection.rules，每项形如 "GET /products/* fault=status status=503 probability=0.5 times=2"
+func (c *Config) FaultRules() ([]FaultRule, error) {
+	rules := make([]FaultRule, 0, len(c.FaultInjection.Rules))
+	for _, spec := range c.FaultInjection.Rules {
+		fields := strings.Fields(spec)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%q: expected method and path", spec)
+		}
+
+		rule := FaultRule{Method: fields[0], Path: fields[1], Probability: 1}
+		for _, field := range fields[2:] {
+			name, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("%q: expected name=value, got %q", spec, field)
+			}
+			var err error
+			switch name {
+			case "fault":
+				rule.Fault = value
+			case "status":
+				rule.Status, err = strconv.Atoi(value)
+			case "latency":
+				rule.Latency, err = time.ParseDuration(value)
+			case "retry_after":
+				rule.RetryAfter, err = time.ParseDuration(value)
+			case "probability":
+				rule.Probability, err = strconv.ParseFloat(value, 64)
+			case "times":
+				rule.Times, err = strconv.Atoi(value)
+			default:
+				return nil, fmt.Errorf("%q: unknown field %q, expected fault, status, latency, retry_after, probability or times", spec, name)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%q: invalid %s: %v", spec, name, err)
+			}
+		}
+		if rule.Fault == "" {
+			return nil, fmt.Errorf("%q: missing fault", spec)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
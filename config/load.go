@@ -0,0 +1,46 @@
+package config
+
+// Option 配置加载选项
+type Option func(*Overrides)
+
+// WithEnv 使用指定的环境变量查找函数代替 os.LookupEnv
+func WithEnv(lookup func(name string) (string, bool)) Option {
+	return func(o *Overrides) {
+		o.Env = lookup
+	}
+}
+
+// WithoutEnv 忽略所有环境变量
+func WithoutEnv() Option {
+	return WithEnv(func(string) (string, bool) { return "", false })
+}
+
+// WithFlags 以命令行参数的优先级覆盖配置项，键为配置项名称，如 api.base_url
+func WithFlags(flags map[string]string) Option {
+	return func(o *Overrides) {
+		if o.Flags == nil {
+			o.Flags = make(map[string]string)
+		}
+		for key, value := range flags {
+			o.Flags[key] = value
+		}
+	}
+}
+
+// Load 从指定配置文件加载一份独立的配置并校验，不影响 GetConfig 返回的全局配置。
+// path 为空时与 GetConfig 一样在 . 和 ./config 中查找 config.yaml
+func Load(path string, opts ...Option) (*Config, error) {
+	o := Overrides{ConfigFile: path}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	config, err := Resolve(o)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HostLimit 单个主机的限速配置
+type HostLimit struct {
+	Host        string  // 主机名，可带端口，如 fakestoreapi.com 或 localhost:3000
+	RPS         float64 // 每秒允许的请求数，0表示不限速
+	Burst       int     // 令牌桶容量
+	MaxInFlight int     // 同时进行的请求数上限，0表示不限制
+}
+
+// HostLimits 解析 rate_limit.hosts，每项形如 "fakestoreapi.com rps=5 burst=10 max_in_flight=4"，
+// 未指定的字段使用 rate_limit 中的全局值
+func (c *Config) HostLimits() ([]HostLimit, error) {
+	limits := make([]HostLimit, 0, len(c.RateLimit.Hosts))
+	for _, spec := range c.RateLimit.Hosts {
+		fields := strings.Fields(spec)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty host limit")
+		}
+
+		limit := HostLimit{
+			Host:        fields[0],
+			RPS:         c.RateLimit.RPS,
+			Burst:       c.RateLimit.Burst,
+			MaxInFlight: c.RateLimit.MaxInFlight,
+		}
+		for _, field := range fields[1:] {
+			name, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("%q: expected name=value, got %q", spec, field)
+			}
+			var err error
+			switch name {
+			case "rps":
+				limit.RPS, err = strconv.ParseFloat(value, 64)
+			case "burst":
+				limit.Burst, err = strconv.Atoi(value)
+			case "max_in_flight":
+				limit.MaxInFlight, err = strconv.Atoi(value)
+			default:
+				return nil, fmt.Errorf("%q: unknown field %q, expected rps, burst or max_in_flight", spec, name)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%q: invalid %s: %v", spec, name, err)
+			}
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	maxTimeout    = 300 // api.timeout 上限（秒）
+	maxRetryCount = 10  // api.retry_count 上限
+)
+
+var (
+	logLevels        = []string{"debug", "info", "warn", "error"}
+	logFormats       = []string{"json", "text"}
+	logOutputs       = []string{"console", "file"}
+	harScopes        = []string{"test", "run"}
+	vcrModes         = []string{"passthrough", "record", "replay"}
+	httpMethods      = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	vcrFormats       = []string{"yaml", "json"}
+	vcrMatches       = []string{"method", "path", "query", "body"}
+	preflightActions = []string{"skip", "abort", "warn"}
+	strictModes      = []string{"off", "report", "fail"}
+	faultKinds       = []string{"latency", "reset", "timeout", "truncate", "malformed", "status"}
+	openAPIModes     = []string{"report", "fail"}
+)
+
+// Problem 单个配置问题
+type Problem struct {
+	Key     string
+	Message string
+}
+
+// ValidationError 汇总了配置中的所有问题
+type ValidationError struct {
+	Problems []Problem
+}
+
+// Error 实现 error 接口，每个问题占一行
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config (%d problems):", len(e.Problems))
+	for _, p := range e.Problems {
+		fmt.Fprintf(&b, "\n  - %s: %s", p.Key, p.Message)
+	}
+	return b.String()
+}
+
+// Validate 校验配置，返回包含所有问题的 *ValidationError，配置有效时返回nil
+func (c *Config) Validate() error {
+	var problems []Problem
+	add := func(key, format string, args ...interface{}) {
+		problems = append(problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if u, err := url.Parse(c.API.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		add("api.base_url", "%q is not an absolute http(s) URL", c.API.BaseURL)
+	}
+	if c.API.Timeout <= 0 || c.API.Timeout > maxTimeout {
+		add("api.timeout", "%d is out of range, expected 1-%d seconds", c.API.Timeout, maxTimeout)
+	}
+	if c.API.RetryCount < 0 || c.API.RetryCount > maxRetryCount {
+		add("api.retry_count", "%d is out of range, expected 0-%d", c.API.RetryCount, maxRetryCount)
+	}
+	if !contains(strictModes, c.API.StrictDecoding) {
+		add("api.strict_decoding", "%q is not one of %s", c.API.StrictDecoding, strings.Join(strictModes, ", "))
+	}
+
+	for _, status := range c.Retry.Statuses {
+		if status < 400 || status > 599 {
+			add("retry.statuses", "%d is not a 4xx or 5xx status code", status)
+		}
+	}
+	if c.Retry.WaitTime < 0 {
+		add("retry.wait_time", "%v must not be negative", c.Retry.WaitTime)
+	}
+	if c.Retry.MaxWaitTime < c.Retry.WaitTime {
+		add("retry.max_wait_time", "%v is less than retry.wait_time %v", c.Retry.MaxWaitTime, c.Retry.WaitTime)
+	}
+	if c.Retry.Jitter < 0 || c.Retry.Jitter > 1 {
+		add("retry.jitter", "%v is out of range, expected 0-1", c.Retry.Jitter)
+	}
+	for _, method := range c.Retry.ExcludeMethods {
+		// 与 RetryPolicy.ShouldRetry 一致，方法不区分大小写
+		if !contains(httpMethods, strings.ToUpper(method)) {
+			add("retry.exclude_methods", "%q is not one of %s", method, strings.Join(httpMethods, ", "))
+		}
+	}
+
+	if c.RateLimit.RPS < 0 {
+		add("rate_limit.rps", "%v must not be negative", c.RateLimit.RPS)
+	}
+	if c.RateLimit.Burst < 1 {
+		add("rate_limit.burst", "%d must be at least 1", c.RateLimit.Burst)
+	}
+	if c.RateLimit.MaxInFlight < 0 {
+		add("rate_limit.max_in_flight", "%d must not be negative", c.RateLimit.MaxInFlight)
+	}
+	limits, err := c.HostLimits()
+	if err != nil {
+		add("rate_limit.hosts", "%v", err)
+	}
+	for _, l := range limits {
+		if l.RPS < 0 || l.Burst < 1 || l.MaxInFlight < 0 {
+			add("rate_limit.hosts", "%s: rps and max_in_flight must not be negative, burst must be at least 1", l.Host)
+		}
+	}
+
+	if c.CircuitBreaker.Enabled {
+		if c.CircuitBreaker.FailureThreshold < 1 {
+			add("circuit_breaker.failure_threshold", "%d must be at least 1", c.CircuitBreaker.FailureThreshold)
+		}
+		if c.CircuitBreaker.Cooldown <= 0 {
+			add("circuit_breaker.cooldown", "%v must be positive", c.CircuitBreaker.Cooldown)
+		}
+	}
+
+	rules, err := c.FaultRules()
+	if err != nil {
+		add("fault_injection.rules", "%v", err)
+	}
+	for _, r := range rules {
+		name := r.Method + " " + r.Path
+		if r.Method != "*" && !contains(httpMethods, r.Method) {
+			add("fault_injection.rules", "%s: method must be * or one of %s", name, strings.Join(httpMethods, ", "))
+		}
+		if !strings.HasPrefix(r.Path, "/") {
+			add("fault_injection.rules", "%s: path must start with /", name)
+		} else if _, err := path.Match(r.Path, ""); err != nil {
+			add("fault_injection.rules", "%s: invalid path pattern: %v", name, err)
+		}
+		if !contains(faultKinds, r.Fault) {
+			add("fault_injection.rules", "%s: fault %q is not one of %s", name, r.Fault, strings.Join(faultKinds, ", "))
+		}
+		if r.Fault == "status" && (r.Status < 400 || r.Status > 599) {
+			add("fault_injection.rules", "%s: status %d must be between 400 and 599", name, r.Status)
+		}
+		if r.Fault == "latency" && r.Latency <= 0 {
+			add("fault_injection.rules", "%s: latency must be positive", name)
+		}
+		if r.Latency < 0 || r.RetryAfter < 0 || r.Times < 0 {
+			add("fault_injection.rules", "%s: latency, retry_after and times must not be negative", name)
+		}
+		if r.Probability < 0 || r.Probability > 1 {
+			add("fault_injection.rules", "%s: probability %v must be between 0 and 1", name, r.Probability)
+		}
+	}
+
+	if (c.Auth.Username == "") != (c.Auth.Password == "") {
+		add("auth", "username and password must be set together")
+	}
+
+	if c.SLA.ListResponseTime <= 0 {
+		add("sla.list_response_time", "%v must be positive", c.SLA.ListResponseTime)
+	}
+	if c.SLA.ItemResponseTime <= 0 {
+		add("sla.item_response_time", "%v must be positive", c.SLA.ItemResponseTime)
+	}
+
+	if c.Preflight.Enabled {
+		if !contains(preflightActions, c.Preflight.OnFailure) {
+			add("preflight.on_failure", "%q is not one of %s", c.Preflight.OnFailure, strings.Join(preflightActions, ", "))
+		}
+		for _, endpoint := range c.Preflight.Endpoints {
+			if !strings.HasPrefix(endpoint, "/") {
+				add("preflight.endpoints", "%q must start with /", endpoint)
+			}
+		}
+		if c.Preflight.Timeout <= 0 {
+			add("preflight.timeout", "%v must be positive", c.Preflight.Timeout)
+		}
+	}
+
+	if !contains(harScopes, c.HAR.Scope) {
+		add("har.scope", "%q is not one of %s", c.HAR.Scope, strings.Join(harScopes, ", "))
+	}
+
+	if !contains(vcrModes, c.VCR.Mode) {
+		add("vcr.mode", "%q is not one of %s", c.VCR.Mode, strings.Join(vcrModes, ", "))
+	}
+	if !contains(vcrFormats, c.VCR.Format) {
+		add("vcr.format", "%q is not one of %s", c.VCR.Format, strings.Join(vcrFormats, ", "))
+	}
+	if len(c.VCR.Match) == 0 {
+		add("vcr.match", "at least one of %s is required", strings.Join(vcrMatches, ", "))
+	}
+	for _, m := range c.VCR.Match {
+		if !contains(vcrMatches, m) {
+			add("vcr.match", "%q is not one of %s", m, strings.Join(vcrMatches, ", "))
+		}
+	}
+	if c.VCR.Mode == "record" {
+		if err := checkWritableDir(c.VCR.Dir); err != nil {
+			add("vcr.dir", "%v", err)
+		}
+	}
+
+	if !contains(openAPIModes, c.OpenAPI.Mode) {
+		add("openapi.mode", "%q is not one of %s", c.OpenAPI.Mode, strings.Join(openAPIModes, ", "))
+	}
+	if c.OpenAPI.Enabled {
+		if c.OpenAPI.Spec == "" {
+			add("openapi.spec", "must be set when openapi.enabled is true")
+		} else if info, err := os.Stat(c.OpenAPI.Spec); err != nil {
+			add("openapi.spec", "%v", err)
+		} else if info.IsDir() {
+			add("openapi.spec", "%s is a directory", c.OpenAPI.Spec)
+		}
+	}
+
+	if !contains(logLevels, c.Logging.Level) {
+		add("logging.level", "%q is not one of %s", c.Logging.Level, strings.Join(logLevels, ", "))
+	}
+	if !contains(logFormats, c.Logging.Format) {
+		add("logging.format", "%q is not one of %s", c.Logging.Format, strings.Join(logFormats, ", "))
+	}
+	if !contains(logOutputs, c.Logging.Output) {
+		add("logging.output", "%q is not one of %s", c.Logging.Output, strings.Join(logOutputs, ", "))
+	}
+	if c.Logging.Output == "file" {
+		if c.Logging.File == "" {
+			add("logging.file", "must be set when logging.output is file")
+		} else if err := checkWritableDir(filepath.Dir(c.Logging.File)); err != nil {
+			add("logging.file", "%v", err)
+		}
+	}
+
+	if err := checkWritableDir(c.Allure.ResultsDir); err != nil {
+		add("allure.results_dir", "%v", err)
+	}
+	if err := checkWritableDir(c.Allure.ReportDir); err != nil {
+		add("allure.report_dir", "%v", err)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkWritableDir 检查目录可写；目录不存在时检查最近的已存在上级目录，不会创建目录
+func checkWritableDir(dir string) error {
+	if dir == "" {
+		return fmt.Errorf("directory must not be empty")
+	}
+
+	path := filepath.Clean(dir)
+	for {
+		info, err := os.Stat(path)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%q is not a directory", path)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return fmt.Errorf("no existing parent directory for %q", dir)
+		}
+		path = parent
+	}
+
+	f, err := os.CreateTemp(path, ".apitest-write-check-*")
+	if err != nil {
+		return fmt.Errorf("%q is not writable: %v", path, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// contains 判断切片是否包含指定值
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+package fakestore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/models"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// clientBaseURL Client 构造的请求地址前缀，不会被访问
+const clientBaseURL = "http://fakestore.invalid"
+
+// Client 不经过网络的 client.StoreAPI 实现：请求直接交给 NewHandler 处理，
+// 状态码、错误信息和 PUT/PATCH 语义与替身服务完全相同，错误与 APIClient 一样由 client.CheckResponse 构造。
+// 用于对依赖 client.StoreAPI 的辅助函数做单元测试
+type Client struct {
+	store *Store
+	http  *resty.Client
+
+	mu        sync.RWMutex
+	token     string
+	requestID atomic.Int64
+}
+
+// Client 实现 client.StoreAPI，方法签名变化时编译失败
+var _ client.StoreAPI = (*Client)(nil)
+
+// NewClient 创建读写 store 的 Client，store 为nil时使用新的初始数据
+func NewClient(store *Store) *Client {
+	if store == nil {
+		store = NewStore()
+	}
+	c := &Client{store: store}
+	c.http = resty.New().
+		SetBaseURL(clientBaseURL).
+		SetTransport(handlerTransport{handler: NewHandler(store)}).
+		SetHeaders(map[string]string{
+			"Content-Type": "application/json",
+			"Accept":       "application/json",
+		})
+	// 与 APIClient 一致，每个请求带有请求ID，认证令牌按请求设置
+	c.http.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+		req.SetHeader("X-Request-ID", fmt.Sprintf("fakestore-%d", c.requestID.Add(1)))
+		if token := c.authToken(); token != "" {
+			req.SetAuthToken(token)
+		}
+		return nil
+	})
+	return c
+}
+
+// Store 返回 Client 读写的内存存储
+func (c *Client) Store() *Store {
+	return c.store
+}
+
+// SetAuthToken 设置认证令牌，之后的请求带有 Authorization 头
+func (c *Client) SetAuthToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// authToken 返回当前的认证令牌
+func (c *Client) authToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// do 发送请求，body 不为nil时以JSON发送，2xx响应解码到 result
+func (c *Client) do(ctx context.Context, method, path string, body, result interface{}) error {
+	req := c.http.R().SetContext(ctx).SetResult(result)
+	if body != nil {
+		req.SetBody(body)
+	}
+	resp, err := req.Execute(method, path)
+	return client.CheckResponse(ctx, resp, err)
+}
+
+// handlerTransport 把请求交给 handler 处理并返回记录下的响应的 http.RoundTripper
+type handlerTransport struct {
+	handler http.Handler
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+	// 与真实传输一致，已取消或超时的请求不交给 handler
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	served := req.Clone(req.Context())
+	if served.Body == nil {
+		served.Body = http.NoBody
+	}
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, served)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// GetAllProducts 获取所有商品
+func (c *Client) GetAllProducts(ctx context.Context) ([]models.Product, error) {
+	var products []models.Product
+	err := c.do(ctx, http.MethodGet, "/products", nil, &products)
+	return products, err
+}
+
+// GetProductByID 根据ID获取商品
+func (c *Client) GetProductByID(ctx context.Context, id int) (*models.Product, error) {
+	var product models.Product
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/products/%d", id), nil, &product)
+	return &product, err
+}
+
+// GetProductsByLimit 获取限定数量的商品
+func (c *Client) GetProductsByLimit(ctx context.Context, limit int) ([]models.Product, error) {
+	var products []models.Product
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/products?limit=%d", limit), nil, &products)
+	return products, err
+}
+
+// GetProductsBySort 获取排序后的商品
+func (c *Client) GetProductsBySort(ctx context.Context, sort string) ([]models.Product, error) {
+	var products []models.Product
+	err := c.do(ctx, http.MethodGet, "/products?sort="+url.QueryEscape(sort), nil, &products)
+	return products, err
+}
+
+// CreateProduct 创建新商品
+func (c *Client) CreateProduct(ctx context.Context, product models.CreateProductRequest) (*models.Product, error) {
+	var created models.Product
+	err := c.do(ctx, http.MethodPost, "/products", product, &created)
+	return &created, err
+}
+
+// UpdateProduct 替换商品字段，评分保持不变
+func (c *Client) UpdateProduct(ctx context.Context, id int, product models.UpdateProductRequest) (*models.Product, error) {
+	var saved models.Product
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/products/%d", id), product, &saved)
+	return &saved, err
+}
+
+// PatchProduct 只覆盖请求中出现的商品字段
+func (c *Client) PatchProduct(ctx context.Context, id int, product models.UpdateProductRequest) (*models.Product, error) {
+	var saved models.Product
+	err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/products/%d", id), product, &saved)
+	return &saved, err
+}
+
+// DeleteProduct 删除商品
+func (c *Client) DeleteProduct(ctx context.Context, id int) (*models.Product, error) {
+	var product models.Product
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/products/%d", id), nil, &product)
+	return &product, err
+}
+
+// GetAllCategories 获取所有商品分类
+func (c *Client) GetAllCategories(ctx context.Context) ([]string, error) {
+	var categories []string
+	err := c.do(ctx, http.MethodGet, "/products/categories", nil, &categories)
+	return categories, err
+}
+
+// GetProductsByCategory 根据分类获取商品
+func (c *Client) GetProductsByCategory(ctx context.Context, category string) ([]models.Product, error) {
+	var products []models.Product
+	err := c.do(ctx, http.MethodGet, "/products/category/"+url.PathEscape(category), nil, &products)
+	return products, err
+}
+
+// GetAllCarts 获取所有购物车
+func (c *Client) GetAllCarts(ctx context.Context) ([]models.Cart, error) {
+	var carts []models.Cart
+	err := c.do(ctx, http.MethodGet, "/carts", nil, &carts)
+	return carts, err
+}
+
+// GetCartByID 根据ID获取购物车
+func (c *Client) GetCartByID(ctx context.Context, id int) (*models.Cart, error) {
+	var cart models.Cart
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/carts/%d", id), nil, &cart)
+	return &cart, err
+}
+
+// GetCartsByLimit 获取限定数量的购物车
+func (c *Client) GetCartsByLimit(ctx context.Context, limit int) ([]models.Cart, error) {
+	var carts []models.Cart
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/carts?limit=%d", limit), nil, &carts)
+	return carts, err
+}
+
+// GetCartsBySort 获取排序后的购物车
+func (c *Client) GetCartsBySort(ctx context.Context, sort string) ([]models.Cart, error) {
+	var carts []models.Cart
+	err := c.do(ctx, http.MethodGet, "/carts?sort="+url.QueryEscape(sort), nil, &carts)
+	return carts, err
+}
+
+// GetCartsByDateRange 获取指定日期范围内的购物车，起止日期均包含在内
+func (c *Client) GetCartsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]models.Cart, error) {
+	query := url.Values{}
+	query.Set("startdate", startDate.Format(models.DateLayout))
+	query.Set("enddate", endDate.Format(models.DateLayout))
+	var carts []models.Cart
+	err := c.do(ctx, http.MethodGet, "/carts?"+query.Encode(), nil, &carts)
+	return carts, err
+}
+
+// GetCartsByUserID 获取指定用户的购物车
+func (c *Client) GetCartsByUserID(ctx context.Context, userID int) ([]models.Cart, error) {
+	var carts []models.Cart
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/carts/user/%d", userID), nil, &carts)
+	return carts, err
+}
+
+// CreateCart 创建新购物车
+func (c *Client) CreateCart(ctx context.Context, cart models.CreateCartRequest) (*models.Cart, error) {
+	var created models.Cart
+	err := c.do(ctx, http.MethodPost, "/carts", cart, &created)
+	return &created, err
+}
+
+// UpdateCart 替换购物车字段
+func (c *Client) UpdateCart(ctx context.Context, id int, cart models.UpdateCartRequest) (*models.Cart, error) {
+	var saved models.Cart
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/carts/%d", id), cart, &saved)
+	return &saved, err
+}
+
+// PatchCart 只覆盖请求中出现的购物车字段
+func (c *Client) PatchCart(ctx context.Context, id int, cart models.UpdateCartRequest) (*models.Cart, error) {
+	var saved models.Cart
+	err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/carts/%d", id), cart, &saved)
+	return &saved, err
+}
+
+// DeleteCart 删除购物车
+func (c *Client) DeleteCart(ctx context.Context, id int) (*models.Cart, error) {
+	var cart models.Cart
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/carts/%d", id), nil, &cart)
+	return &cart, err
+}
+
+// GetAllUsers 获取所有用户
+func (c *Client) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	err := c.do(ctx, http.MethodGet, "/users", nil, &users)
+	return users, err
+}
+
+// GetUserByID 根据ID获取用户
+func (c *Client) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	var user models.User
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/users/%d", id), nil, &user)
+	return &user, err
+}
+
+// GetUsersByLimit 获取限定数量的用户
+func (c *Client) GetUsersByLimit(ctx context.Context, limit int) ([]models.User, error) {
+	var users []models.User
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/users?limit=%d", limit), nil, &users)
+	return users, err
+}
+
+// GetUsersBySort 获取排序后的用户
+func (c *Client) GetUsersBySort(ctx context.Context, sort string) ([]models.User, error) {
+	var users []models.User
+	err := c.do(ctx, http.MethodGet, "/users?sort="+url.QueryEscape(sort), nil, &users)
+	return users, err
+}
+
+// AddUser 添加新用户
+func (c *Client) AddUser(ctx context.Context, user models.CreateUserRequest) (*models.User, error) {
+	var created models.User
+	err := c.do(ctx, http.MethodPost, "/users", user, &created)
+	return &created, err
+}
+
+// UpdateUser 替换用户字段
+func (c *Client) UpdateUser(ctx context.Context, id int, user models.UpdateUserRequest) (*models.User, error) {
+	var saved models.User
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/users/%d", id), user, &saved)
+	return &saved, err
+}
+
+// PatchUser 只覆盖请求中出现的用户字段
+func (c *Client) PatchUser(ctx context.Context, id int, user models.UpdateUserRequest) (*models.User, error) {
+	var saved models.User
+	err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/users/%d", id), user, &saved)
+	return &saved, err
+}
+
+// DeleteUser 删除用户
+func (c *Client) DeleteUser(ctx context.Context, id int) (*models.User, error) {
+	var user models.User
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/users/%d", id), nil, &user)
+	return &user, err
+}
+
+// Login 用户登录
+func (c *Client) Login(ctx context.Context, loginReq models.LoginRequest) (*models.LoginResponse, error) {
+	var login models.LoginResponse
+	err := c.do(ctx, http.MethodPost, "/auth/login", loginReq, &login)
+	return &login, err
+}
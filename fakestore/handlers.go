@@ -0,0 +1,435 @@
+package fakestore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-testify-allure-api-test/models"
+)
+
+// tokenSecret 生成登录令牌签名所用的密钥
+var tokenSecret = []byte("fakestore-secret")
+
+// handler 实现 Fake Store API 路由的HTTP处理器
+type handler struct {
+	store *Store
+}
+
+// NewHandler 创建基于指定存储的HTTP处理器
+func NewHandler(store *Store) http.Handler {
+	h := &handler{store: store}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/products", h.products)
+	mux.HandleFunc("/products/", h.product)
+	mux.HandleFunc("/carts", h.carts)
+	mux.HandleFunc("/carts/", h.cart)
+	mux.HandleFunc("/users", h.users)
+	mux.HandleFunc("/users/", h.user)
+	mux.HandleFunc("/auth/login", h.login)
+
+	// 与常见网关一致，回显客户端传入的请求ID
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get("X-Request-ID"); id != "" {
+			w.Header().Set("X-Request-ID", id)
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// products 处理 /products
+func (h *handler) products(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		products, err := applyListQuery(h.store.Products(), r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, products)
+	case http.MethodPost:
+		var req models.CreateProductRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid product body: "+err.Error())
+			return
+		}
+		product := h.store.AddProduct(models.Product{
+			Title:       req.Title,
+			Price:       req.Price,
+			Description: req.Description,
+			Image:       req.Image,
+			Category:    req.Category,
+		})
+		writeJSON(w, http.StatusOK, product)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// product 处理 /products/{id}、/products/categories 和 /products/category/{c}
+func (h *handler) product(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/products/")
+
+	switch {
+	case rest == "categories":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, h.store.Categories())
+		return
+	case strings.HasPrefix(rest, "category/"):
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		category := strings.TrimPrefix(rest, "category/")
+		matched := []models.Product{}
+		for _, p := range h.store.Products() {
+			if p.Category == category {
+				matched = append(matched, p)
+			}
+		}
+		products, err := applyListQuery(matched, r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, products)
+		return
+	}
+
+	id, ok := parseID(rest)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "product id should be provided")
+		return
+	}
+	existing, found := h.store.Product(id)
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("product with id %d not found", id))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, existing)
+	case http.MethodPut, http.MethodPatch:
+		// PUT 替换商品字段，PATCH 仅覆盖请求体中出现的字段
+		updated := existing
+		if r.Method == http.MethodPut {
+			updated = models.Product{Rating: existing.Rating}
+		}
+		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid product body: "+err.Error())
+			return
+		}
+		updated.ID = id
+		h.store.SaveProduct(updated)
+		writeJSON(w, http.StatusOK, updated)
+	case http.MethodDelete:
+		deleted, _ := h.store.DeleteProduct(id)
+		writeJSON(w, http.StatusOK, deleted)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// carts 处理 /carts
+func (h *handler) carts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		carts, err := filterCartsByDate(h.store.Carts(), r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		carts, err = applyListQuery(carts, r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, carts)
+	case http.MethodPost:
+		var req models.CreateCartRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid cart body: "+err.Error())
+			return
+		}
+		if req.UserID <= 0 {
+			writeError(w, http.StatusBadRequest, "userId should be provided")
+			return
+		}
+		cart := h.store.AddCart(models.Cart{
+			UserID:   req.UserID,
+			Date:     req.Date,
+			Products: req.Products,
+		})
+		writeJSON(w, http.StatusOK, cart)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// cart 处理 /carts/{id} 和 /carts/user/{id}
+func (h *handler) cart(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/carts/")
+
+	if strings.HasPrefix(rest, "user/") {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		userID, ok := parseID(strings.TrimPrefix(rest, "user/"))
+		if !ok {
+			writeError(w, http.StatusBadRequest, "user id should be provided")
+			return
+		}
+		matched := []models.Cart{}
+		for _, c := range h.store.Carts() {
+			if c.UserID == userID {
+				matched = append(matched, c)
+			}
+		}
+		carts, err := applyListQuery(matched, r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, carts)
+		return
+	}
+
+	id, ok := parseID(rest)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "cart id should be provided")
+		return
+	}
+	existing, found := h.store.Cart(id)
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("cart with id %d not found", id))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, existing)
+	case http.MethodPut, http.MethodPatch:
+		// PUT 替换购物车字段，PATCH 仅覆盖请求体中出现的字段
+		updated := existing
+		if r.Method == http.MethodPut {
+			updated = models.Cart{}
+		}
+		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid cart body: "+err.Error())
+			return
+		}
+		updated.ID = id
+		h.store.SaveCart(updated)
+		writeJSON(w, http.StatusOK, updated)
+	case http.MethodDelete:
+		deleted, _ := h.store.DeleteCart(id)
+		writeJSON(w, http.StatusOK, deleted)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// users 处理 /users
+func (h *handler) users(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		users, err := applyListQuery(h.store.Users(), r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, users)
+	case http.MethodPost:
+		var req models.CreateUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid user body: "+err.Error())
+			return
+		}
+		if req.Username == "" || req.Password == "" || req.Email == "" {
+			writeError(w, http.StatusBadRequest, "username, password and email should be provided")
+			return
+		}
+		user, ok := h.store.AddUser(models.User{
+			Email:    req.Email,
+			Username: req.Username,
+			Password: req.Password,
+			Name:     req.Name,
+			Address:  req.Address,
+			Phone:    req.Phone,
+		})
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("username %q already exists", req.Username))
+			return
+		}
+		writeJSON(w, http.StatusOK, user)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// user 处理 /users/{id}
+func (h *handler) user(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseID(strings.TrimPrefix(r.URL.Path, "/users/"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "user id should be provided")
+		return
+	}
+	existing, found := h.store.User(id)
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("user with id %d not found", id))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, existing)
+	case http.MethodPut, http.MethodPatch:
+		// PUT 替换用户字段，PATCH 仅覆盖请求体中出现的字段
+		updated := existing
+		if r.Method == http.MethodPut {
+			updated = models.User{}
+		}
+		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid user body: "+err.Error())
+			return
+		}
+		updated.ID = id
+		h.store.SaveUser(updated)
+		writeJSON(w, http.StatusOK, updated)
+	case http.MethodDelete:
+		deleted, _ := h.store.DeleteUser(id)
+		writeJSON(w, http.StatusOK, deleted)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// login 处理 /auth/login
+func (h *handler) login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Username == "" || req.Password == "" {
+		writeError(w, http.StatusBadRequest, "username and password are not provided in JSON format")
+		return
+	}
+	user, ok := h.store.Authenticate(req.Username, req.Password)
+	if !ok {
+		// 与真实API一致，凭据错误时返回纯文本
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, "username or password is incorrect")
+		return
+	}
+	writeJSON(w, http.StatusOK, models.LoginResponse{Token: issueToken(user)})
+}
+
+// applyListQuery 按 sort 和 limit 查询参数处理列表
+func applyListQuery[T any](items []T, r *http.Request) ([]T, error) {
+	query := r.URL.Query()
+
+	switch sortOrder := query.Get("sort"); sortOrder {
+	case "", "asc":
+	case "desc":
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	default:
+		return nil, fmt.Errorf("invalid sort value %q, expected asc or desc", sortOrder)
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid limit value %q", raw)
+		}
+		if limit < len(items) {
+			items = items[:limit]
+		}
+	}
+	return items, nil
+}
+
+// filterCartsByDate 按 startdate 和 enddate 查询参数过滤购物车，起止日期均包含在内
+func filterCartsByDate(carts []models.Cart, r *http.Request) ([]models.Cart, error) {
+	query := r.URL.Query()
+	start, end := time.Time{}, time.Time{}
+
+	if raw := query.Get("startdate"); raw != "" {
+		t, err := time.Parse(models.DateLayout, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid startdate value %q", raw)
+		}
+		start = t
+	}
+	if raw := query.Get("enddate"); raw != "" {
+		t, err := time.Parse(models.DateLayout, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid enddate value %q", raw)
+		}
+		end = t.AddDate(0, 0, 1)
+	}
+
+	filtered := []models.Cart{}
+	for _, c := range carts {
+		if !start.IsZero() && c.Date.Before(start) {
+			continue
+		}
+		if !end.IsZero() && !c.Date.Before(end) {
+			continue
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered, nil
+}
+
+// parseID 解析路径中的正整数ID
+func parseID(raw string) (int, bool) {
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// issueToken 为用户签发JWT格式的令牌
+func issueToken(user models.User) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	header := encode([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := encode([]byte(fmt.Sprintf(`{"sub":%d,"user":%q,"iat":%d}`, user.ID, user.Username, time.Now().Unix())))
+
+	mac := hmac.New(sha256.New, tokenSecret)
+	mac.Write([]byte(header + "." + payload))
+	return header + "." + payload + "." + encode(mac.Sum(nil))
+}
+
+// writeJSON 以JSON格式写入响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError 写入 models.ErrorResponse 格式的错误响应
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, models.ErrorResponse{Message: message, Code: status})
+}
+
+// methodNotAllowed 写入405响应
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
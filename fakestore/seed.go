@@ -0,0 +1,128 @@
+package fakestore
+
+import (
+	"time"
+
+	"go-testify-allure-api-test/models"
+)
+
+// imageBase 商品图片的基础地址，与真实 Fake Store API 保持一致
+const imageBase = "https://fakestoreapi.com/img/"
+
+// seedProducts 初始商品数据（取自 fakestoreapi.com 的公开数据）
+func seedProducts() []models.Product {
+	return []models.Product{
+		{ID: 1, Title: "Fjallraven - Foldsack No. 1 Backpack, Fits 15 Laptops", Price: 109.95, Category: "men's clothing",
+			Description: "Your perfect pack for everyday use and walks in the forest. Stash your laptop (up to 15 inches) in the padded sleeve, your everyday",
+			Image:       imageBase + "81fPKd-2AYL._AC_SL1500_.jpg", Rating: models.Rating{Rate: 3.9, Count: 120}},
+		{ID: 2, Title: "Mens Casual Premium Slim Fit T-Shirts ", Price: 22.3, Category: "men's clothing",
+			Description: "Slim-fitting style, contrast raglan long sleeve, three-button henley placket, light weight & soft fabric for breathable and comfortable wearing.",
+			Image:       imageBase + "71-3HjGNDUL._AC_SY879._SX._UX._SY._UY_.jpg", Rating: models.Rating{Rate: 4.1, Count: 259}},
+		{ID: 3, Title: "Mens Cotton Jacket", Price: 55.99, Category: "men's clothing",
+			Description: "Great outerwear jackets for Spring/Autumn/Winter, suitable for many occasions, such as working, hiking, camping, mountain/rock climbing, cycling, traveling or other outdoors.",
+			Image:       imageBase + "71li-ujtlUL._AC_UX679_.jpg", Rating: models.Rating{Rate: 4.7, Count: 500}},
+		{ID: 4, Title: "Mens Casual Slim Fit", Price: 15.99, Category: "men's clothing",
+			Description: "The color could be slightly different between on the screen and in practice. Please note that body builds vary by person.",
+			Image:       imageBase + "71YXzeOuslL._AC_UY879_.jpg", Rating: models.Rating{Rate: 2.1, Count: 430}},
+		{ID: 5, Title: "John Hardy Women's Legends Naga Gold & Silver Dragon Station Chain Bracelet", Price: 695, Category: "jewelery",
+			Description: "From our Legends Collection, the Naga was inspired by the mythical water dragon that protects the ocean's pearl.",
+			Image:       imageBase + "71pWzhdJNwL._AC_UL640_QL65_ML3_.jpg", Rating: models.Rating{Rate: 4.6, Count: 400}},
+		{ID: 6, Title: "Solid Gold Petite Micropave ", Price: 168, Category: "jewelery",
+			Description: "Satisfaction Guaranteed. Return or exchange any order within 30 days. Designed and sold by Hafeez Center in the United States.",
+			Image:       imageBase + "61sbMiUnoGL._AC_UL640_QL65_ML3_.jpg", Rating: models.Rating{Rate: 3.9, Count: 70}},
+		{ID: 7, Title: "White Gold Plated Princess", Price: 9.99, Category: "jewelery",
+			Description: "Classic Created Wedding Engagement Solitaire Diamond Promise Ring for Her. Gifts to spoil your love more for Engagement, Wedding, Anniversary, Valentine's Day...",
+			Image:       imageBase + "71YAIFU48IL._AC_UL640_QL65_ML3_.jpg", Rating: models.Rating{Rate: 3, Count: 400}},
+		{ID: 8, Title: "Pierced Owl Rose Gold Plated Stainless Steel Double", Price: 10.99, Category: "jewelery",
+			Description: "Rose Gold Plated Double Flared Tunnel Plug Earrings. Made of 316L Stainless Steel.",
+			Image:       imageBase + "51UDEzMJVpL._AC_UL640_QL65_ML3_.jpg", Rating: models.Rating{Rate: 1.9, Count: 100}},
+		{ID: 9, Title: "WD 2TB Elements Portable External Hard Drive - USB 3.0 ", Price: 64, Category: "electronics",
+			Description: "USB 3.0 and USB 2.0 Compatibility Fast data transfers Improve PC Performance High Capacity.",
+			Image:       imageBase + "61IBBVJvSDL._AC_SY879_.jpg", Rating: models.Rating{Rate: 3.3, Count: 203}},
+		{ID: 10, Title: "SanDisk SSD PLUS 1TB Internal SSD - SATA III 6 Gb/s", Price: 109, Category: "electronics",
+			Description: "Easy upgrade for faster boot up, shutdown, application load and response. Read/write speeds of up to 535MB/s/450MB/s.",
+			Image:       imageBase + "61U7T1koQqL._AC_SX679_.jpg", Rating: models.Rating{Rate: 2.9, Count: 470}},
+		{ID: 11, Title: "Silicon Power 256GB SSD 3D NAND A55 SLC Cache Performance Boost SATA III 2.5", Price: 109, Category: "electronics",
+			Description: "3D NAND flash are applied to deliver high transfer speeds. Remarkable transfer speeds that enable faster bootup and improved overall system performance.",
+			Image:       imageBase + "71kWymZ+c+L._AC_SX679_.jpg", Rating: models.Rating{Rate: 4.8, Count: 319}},
+		{ID: 12, Title: "WD 4TB Gaming Drive Works with Playstation 4 Portable External Hard Drive", Price: 114, Category: "electronics",
+			Description: "Expand your PS4 gaming experience, Play anywhere Fast and easy, setup Sleek design with high capacity, 3-year manufacturer's limited warranty.",
+			Image:       imageBase + "61mtL65D4cL._AC_SX679_.jpg", Rating: models.Rating{Rate: 4.8, Count: 400}},
+		{ID: 13, Title: "Acer SB220Q bi 21.5 inches Full HD (1920 x 1080) IPS Ultra-Thin", Price: 599, Category: "electronics",
+			Description: "21. 5 inches Full HD (1920 x 1080) widescreen IPS display And Radeon free Sync technology.",
+			Image:       imageBase + "81QpkIctqPL._AC_SX679_.jpg", Rating: models.Rating{Rate: 2.9, Count: 250}},
+		{ID: 14, Title: "Samsung 49-Inch CHG90 144Hz Curved Gaming Monitor (LC49HG90DMNXZA) – Super Ultrawide Screen QLED ", Price: 999.99, Category: "electronics",
+			Description: "49 INCH SUPER ULTRAWIDE 32:9 CURVED GAMING MONITOR with dual 27 inch screen side by side QUANTUM DOT (QLED) TECHNOLOGY.",
+			Image:       imageBase + "81Zt42ioCgL._AC_SX679_.jpg", Rating: models.Rating{Rate: 2.2, Count: 140}},
+		{ID: 15, Title: "BIYLACLESEN Women's 3-in-1 Snowboard Jacket Winter Coats", Price: 56.99, Category: "women's clothing",
+			Description: "Note:The Jackets is US standard size, Please choose size as your usual wear Material: 100% Polyester; Detachable Liner Fabric: Warm Fleece.",
+			Image:       imageBase + "51Y5NI-I5jL._AC_UX679_.jpg", Rating: models.Rating{Rate: 2.6, Count: 235}},
+		{ID: 16, Title: "Lock and Love Women's Removable Hooded Faux Leather Moto Biker Jacket", Price: 29.95, Category: "women's clothing",
+			Description: "100% POLYURETHANE(shell) 100% POLYESTER(lining) 75% POLYESTER 25% COTTON (SWEATER), Faux leather material for style and comfort.",
+			Image:       imageBase + "81XH0e8fefL._AC_UY879_.jpg", Rating: models.Rating{Rate: 2.9, Count: 340}},
+		{ID: 17, Title: "Rain Jacket Women Windbreaker Striped Climbing Raincoats", Price: 39.99, Category: "women's clothing",
+			Description: "Lightweight perfet for trip or casual wear---Long sleeve with hooded, adjustable drawstring waist design.",
+			Image:       imageBase + "71HblAHs5xL._AC_UY879_-2.jpg", Rating: models.Rating{Rate: 3.8, Count: 679}},
+		{ID: 18, Title: "MBJ Women's Solid Short Sleeve Boat Neck V ", Price: 9.85, Category: "women's clothing",
+			Description: "95% RAYON 5% SPANDEX, Made in USA or Imported, Do Not Bleach, Lightweight fabric with great stretch for comfort.",
+			Image:       imageBase + "71z3kpMAYsL._AC_UY879_.jpg", Rating: models.Rating{Rate: 4.7, Count: 130}},
+		{ID: 19, Title: "Opna Women's Short Sleeve Moisture", Price: 7.95, Category: "women's clothing",
+			Description: "100% Polyester, Machine wash, 100% cationic polyester interlock, Machine Wash & Pre Shrunk for a Great Fit.",
+			Image:       imageBase + "51eg55uWmdL._AC_UX679_.jpg", Rating: models.Rating{Rate: 4.5, Count: 146}},
+		{ID: 20, Title: "DANVOUE Womens T Shirt Casual Cotton Short", Price: 12.99, Category: "women's clothing",
+			Description: "95%Cotton,5%Spandex, Features: Casual, Short Sleeve, Letter Print,V-Neck,Fashion Tees.",
+			Image:       imageBase + "61pHAEJ4NML._AC_UX679_.jpg", Rating: models.Rating{Rate: 3.6, Count: 145}},
+	}
+}
+
+// seedUsers 初始用户数据
+func seedUsers() []models.User {
+	user := func(id int, email, username, password, first, last, city, street string, number int, zipcode, lat, long, phone string) models.User {
+		return models.User{
+			ID:       id,
+			Email:    email,
+			Username: username,
+			Password: password,
+			Name:     models.Name{Firstname: first, Lastname: last},
+			Address: models.Address{
+				City:        city,
+				Street:      street,
+				Number:      number,
+				Zipcode:     zipcode,
+				Geolocation: models.Geolocation{Lat: lat, Long: long},
+			},
+			Phone: phone,
+		}
+	}
+
+	return []models.User{
+		user(1, "john@gmail.com", "johnd", "m38rmF$", "john", "doe", "kilcoole", "new road", 7682, "12926-3874", "-37.3159", "81.1496", "1-570-236-7033"),
+		user(2, "morrison@gmail.com", "mor_2314", "83r5^_", "david", "morrison", "kilcoole", "Lovers Ln", 7267, "12926-3874", "-37.3159", "81.1496", "1-570-236-7033"),
+		user(3, "kevin@gmail.com", "kevinryan", "kev02937@", "kevin", "ryan", "Cullman", "Frances Ct", 86, "29567-1452", "40.3467", "-30.1310", "1-567-094-1345"),
+		user(4, "don@gmail.com", "donero", "ewedon", "don", "romer", "San Antonio", "Hunters Creek Dr", 6454, "98234-1734", "50.3467", "-20.1310", "1-765-789-6734"),
+		user(5, "derek@gmail.com", "derek", "jklg*_56", "derek", "powell", "san Antonio", "adams St", 245, "80796-1234", "40.3467", "-40.1310", "1-956-001-1945"),
+		user(6, "david_r@gmail.com", "david_r", "3478*#54", "david", "russell", "el paso", "prospect st", 124, "12346-0456", "20.1677", "-10.6789", "1-678-345-9856"),
+		user(7, "miriam@gmail.com", "snyder", "f238&@*$", "miriam", "snyder", "fresno", "saddle st", 1342, "96378-0245", "10.3456", "20.6419", "1-123-943-0563"),
+		user(8, "william@gmail.com", "hopkins", "William56$hj", "william", "hopkins", "mesa", "vally view ln", 1342, "96378-0245", "50.3456", "10.6419", "1-478-001-0890"),
+		user(9, "kate@gmail.com", "kate_h", "kfejk@*_", "kate", "hale", "miami", "avondale ave", 345, "96378-0245", "40.12456", "20.5419", "1-678-456-1934"),
+		user(10, "jimmie@gmail.com", "jimmie_k", "klein*#%*", "jimmie", "klein", "fort wayne", "oak lawn ave", 526, "10256-4532", "30.24788", "-20.545419", "1-104-001-4567"),
+	}
+}
+
+// seedCarts 初始购物车数据
+func seedCarts() []models.Cart {
+	date := func(value string) time.Time {
+		t, _ := time.Parse(time.RFC3339, value)
+		return t
+	}
+
+	return []models.Cart{
+		{ID: 1, UserID: 1, Date: date("2020-03-02T00:00:00Z"), Products: []models.CartProduct{{ProductID: 1, Quantity: 4}, {ProductID: 2, Quantity: 1}, {ProductID: 3, Quantity: 6}}},
+		{ID: 2, UserID: 1, Date: date("2020-01-02T00:00:00Z"), Products: []models.CartProduct{{ProductID: 2, Quantity: 4}, {ProductID: 1, Quantity: 10}, {ProductID: 5, Quantity: 2}}},
+		{ID: 3, UserID: 2, Date: date("2020-03-01T00:00:00Z"), Products: []models.CartProduct{{ProductID: 1, Quantity: 2}, {ProductID: 9, Quantity: 1}}},
+		{ID: 4, UserID: 3, Date: date("2020-01-01T00:00:00Z"), Products: []models.CartProduct{{ProductID: 1, Quantity: 4}}},
+		{ID: 5, UserID: 3, Date: date("2020-03-01T00:00:00Z"), Products: []models.CartProduct{{ProductID: 7, Quantity: 1}, {ProductID: 8, Quantity: 1}}},
+		{ID: 6, UserID: 4, Date: date("2020-03-01T00:00:00Z"), Products: []models.CartProduct{{ProductID: 10, Quantity: 2}, {ProductID: 12, Quantity: 3}}},
+		{ID: 7, UserID: 8, Date: date("2020-03-01T00:00:00Z"), Products: []models.CartProduct{{ProductID: 18, Quantity: 1}}},
+	}
+}
@@ -0,0 +1,42 @@
+// Package fakestore 提供一个进程内的 Fake Store API 替身服务，
+// 用于在无法访问 fakestoreapi.com 的环境中离线运行测试。
+package fakestore
+
+import (
+	"net/http/httptest"
+)
+
+// Server 基于 httptest 的 Fake Store API 替身服务
+type Server struct {
+	store  *Store
+	server *httptest.Server
+}
+
+// NewServer 启动一个使用初始数据的替身服务
+func NewServer() *Server {
+	store := NewStore()
+	return &Server{
+		store:  store,
+		server: httptest.NewServer(NewHandler(store)),
+	}
+}
+
+// URL 返回服务的基础地址，可直接用作 config.API.BaseURL
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Store 返回服务使用的内存存储
+func (s *Server) Store() *Store {
+	return s.store
+}
+
+// Reset 将服务数据恢复到初始状态
+func (s *Server) Reset() {
+	s.store.Reset()
+}
+
+// Close 关闭服务
+func (s *Server) Close() {
+	s.server.Close()
+}
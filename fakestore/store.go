@@ -0,0 +1,249 @@
+package fakestore
+
+import (
+	"sort"
+	"sync"
+
+	"go-testify-allure-api-test/models"
+)
+
+// Store Fake Store 的内存数据存储，所有方法均可并发调用
+type Store struct {
+	mu sync.RWMutex
+
+	products map[int]models.Product
+	carts    map[int]models.Cart
+	users    map[int]models.User
+
+	nextProductID int
+	nextCartID    int
+	nextUserID    int
+}
+
+// NewStore 创建已填充初始数据的内存存储
+func NewStore() *Store {
+	s := &Store{}
+	s.Reset()
+	return s
+}
+
+// Reset 丢弃所有修改并恢复到初始数据
+func (s *Store) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.products = make(map[int]models.Product)
+	for _, p := range seedProducts() {
+		s.products[p.ID] = p
+	}
+	s.carts = make(map[int]models.Cart)
+	for _, c := range seedCarts() {
+		s.carts[c.ID] = c
+	}
+	s.users = make(map[int]models.User)
+	for _, u := range seedUsers() {
+		s.users[u.ID] = u
+	}
+
+	s.nextProductID = len(s.products) + 1
+	s.nextCartID = len(s.carts) + 1
+	s.nextUserID = len(s.users) + 1
+}
+
+// Products 返回按ID升序排列的所有商品
+func (s *Store) Products() []models.Product {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedByID(s.products, func(p models.Product) int { return p.ID })
+}
+
+// Product 根据ID查找商品
+func (s *Store) Product(id int) (models.Product, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.products[id]
+	return p, ok
+}
+
+// AddProduct 保存新商品并分配ID
+func (s *Store) AddProduct(p models.Product) models.Product {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.ID = s.nextProductID
+	s.nextProductID++
+	s.products[p.ID] = p
+	return p
+}
+
+// SaveProduct 覆盖已存在的商品，商品不存在时返回false
+func (s *Store) SaveProduct(p models.Product) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.products[p.ID]; !ok {
+		return false
+	}
+	s.products[p.ID] = p
+	return true
+}
+
+// DeleteProduct 删除商品并返回被删除的数据
+func (s *Store) DeleteProduct(id int) (models.Product, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.products[id]
+	if ok {
+		delete(s.products, id)
+	}
+	return p, ok
+}
+
+// Categories 返回按字母顺序排列的商品分类
+func (s *Store) Categories() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var categories []string
+	for _, p := range s.products {
+		if !seen[p.Category] {
+			seen[p.Category] = true
+			categories = append(categories, p.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Carts 返回按ID升序排列的所有购物车
+func (s *Store) Carts() []models.Cart {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	carts := sortedByID(s.carts, func(c models.Cart) int { return c.ID })
+	for i := range carts {
+		carts[i] = cloneCart(carts[i])
+	}
+	return carts
+}
+
+// Cart 根据ID查找购物车
+func (s *Store) Cart(id int) (models.Cart, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.carts[id]
+	return cloneCart(c), ok
+}
+
+// AddCart 保存新购物车并分配ID
+func (s *Store) AddCart(c models.Cart) models.Cart {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c = cloneCart(c)
+	c.ID = s.nextCartID
+	s.nextCartID++
+	s.carts[c.ID] = c
+	return cloneCart(c)
+}
+
+// SaveCart 覆盖已存在的购物车，购物车不存在时返回false
+func (s *Store) SaveCart(c models.Cart) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.carts[c.ID]; !ok {
+		return false
+	}
+	s.carts[c.ID] = cloneCart(c)
+	return true
+}
+
+// DeleteCart 删除购物车并返回被删除的数据
+func (s *Store) DeleteCart(id int) (models.Cart, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.carts[id]
+	if ok {
+		delete(s.carts, id)
+	}
+	return c, ok
+}
+
+// Users 返回按ID升序排列的所有用户
+func (s *Store) Users() []models.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedByID(s.users, func(u models.User) int { return u.ID })
+}
+
+// User 根据ID查找用户
+func (s *Store) User(id int) (models.User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.users[id]
+	return u, ok
+}
+
+// AddUser 保存新用户并分配ID，用户名已存在时返回false
+func (s *Store) AddUser(u models.User) (models.User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.users {
+		if existing.Username == u.Username {
+			return models.User{}, false
+		}
+	}
+	u.ID = s.nextUserID
+	s.nextUserID++
+	s.users[u.ID] = u
+	return u, true
+}
+
+// SaveUser 覆盖已存在的用户，用户不存在时返回false
+func (s *Store) SaveUser(u models.User) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[u.ID]; !ok {
+		return false
+	}
+	s.users[u.ID] = u
+	return true
+}
+
+// DeleteUser 删除用户并返回被删除的数据
+func (s *Store) DeleteUser(id int) (models.User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if ok {
+		delete(s.users, id)
+	}
+	return u, ok
+}
+
+// Authenticate 校验用户名和密码，成功时返回对应用户
+func (s *Store) Authenticate(username, password string) (models.User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.users {
+		if u.Username == username && u.Password == password {
+			return u, true
+		}
+	}
+	return models.User{}, false
+}
+
+// sortedByID 将map中的数据按ID升序转换为切片
+func sortedByID[T any](items map[int]T, id func(T) int) []T {
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool { return id(result[i]) < id(result[j]) })
+	return result
+}
+
+// cloneCart 复制购物车，避免调用方修改共享的商品切片
+func cloneCart(c models.Cart) models.Cart {
+	if c.Products != nil {
+		c.Products = append([]models.CartProduct(nil), c.Products...)
+	}
+	return c
+}
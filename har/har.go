@@ -0,0 +1,196 @@
+package har
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Version 生成的 HAR 文件版本
+	Version = "1.2"
+
+	creatorName    = "go-testify-allure-api-test"
+	creatorVersion = "1.0"
+)
+
+// File HAR 文件的根对象
+type File struct {
+	Log Log `json:"log"`
+}
+
+// Log HAR 日志
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+	Comment string  `json:"comment,omitempty"`
+}
+
+// Creator 生成 HAR 的工具
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry 一次请求尝试。重试时每次尝试各对应一个 Entry，_attempt 记录尝试次数
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"` // 写入文件时格式化为 RFC 3339
+	Time            float64   `json:"time"`
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Cache           struct{}  `json:"cache"`
+	Timings         Timings   `json:"timings"`
+	ServerIPAddress string    `json:"serverIPAddress,omitempty"`
+	Comment         string    `json:"comment,omitempty"`
+	Attempt         int       `json:"_attempt"`
+	Error           string    `json:"_error,omitempty"`
+}
+
+// Request HAR 请求
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Response HAR 响应。未收到响应时 status 为0
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// NameValue 请求头、查询参数和 Cookie
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData 请求体
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Content 响应体
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+// Timings 各阶段耗时（毫秒），不适用的阶段为 -1
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Recorder 收集请求记录，可在多个客户端和 goroutine 之间共享
+type Recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder 创建空的记录器
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Add 追加一条记录
+func (r *Recorder) Add(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+// Len 返回已记录的条数
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// File 返回按开始时间排序的 HAR 文件内容
+func (r *Recorder) File(comment string) File {
+	r.mu.Lock()
+	entries := make([]Entry, len(r.entries))
+	copy(entries, r.entries)
+	r.mu.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+	return File{Log: Log{
+		Version: Version,
+		Creator: Creator{Name: creatorName, Version: creatorVersion},
+		Entries: entries,
+		Comment: comment,
+	}}
+}
+
+// WriteFile 把记录写入 path，必要时创建上级目录
+func (r *Recorder) WriteFile(path, comment string) error {
+	data, err := json.MarshalIndent(r.File(comment), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// headerList 把请求头转换为按名称排序的列表
+func headerList(header http.Header) []NameValue {
+	list := []NameValue{}
+	for name, values := range header {
+		for _, value := range values {
+			list = append(list, NameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// queryList 把查询参数转换为按名称排序的列表
+func queryList(query url.Values) []NameValue {
+	list := []NameValue{}
+	for name, values := range query {
+		for _, value := range values {
+			list = append(list, NameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// millis 把耗时转换为毫秒，0 表示该阶段未发生，返回 -1
+func millis(d time.Duration) float64 {
+	if d <= 0 {
+		return -1
+	}
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,110 @@
+package har
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"go-testify-allure-api-test/client"
+)
+
+// Hook 把一次请求尝试记录为 HAR 条目，可通过 client.WithHook 注册。
+// 敏感请求头和请求体字段的值已隐藏，与 Allure 附件和 curl 命令一致
+func (r *Recorder) Hook(_ context.Context, e client.Exchange) {
+	r.Add(NewEntry(e))
+}
+
+// NewEntry 把一次请求尝试转换为 HAR 条目
+func NewEntry(e client.Exchange) Entry {
+	req := e.Request
+	started := req.Time
+	if started.IsZero() {
+		started = time.Now()
+	}
+
+	entry := Entry{
+		StartedDateTime: started,
+		Time:            float64(e.Duration()) / float64(time.Millisecond),
+		Request:         newRequest(e),
+		Response:        newResponse(e),
+		Timings:         Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Send: 0, Wait: 0, Receive: 0},
+		Attempt:         req.Attempt,
+	}
+	if e.Err != nil {
+		entry.Error = e.Err.Error()
+	}
+
+	trace := req.TraceInfo()
+	if trace.TotalTime > 0 {
+		entry.Timings.DNS = millis(trace.DNSLookup)
+		entry.Timings.Connect = millis(trace.TCPConnTime)
+		entry.Timings.SSL = millis(trace.TLSHandshake)
+		entry.Timings.Wait = float64(trace.ServerTime) / float64(time.Millisecond)
+		entry.Timings.Receive = float64(trace.ResponseTime) / float64(time.Millisecond)
+		if trace.RemoteAddr != nil {
+			if host, _, err := net.SplitHostPort(trace.RemoteAddr.String()); err == nil {
+				entry.ServerIPAddress = host
+			}
+		}
+	} else {
+		entry.Timings.Wait = entry.Time
+	}
+	// 等待限速器的时间记为 blocked，HAR 的 time 是各阶段耗时之和
+	if e.LimiterWait > 0 {
+		entry.Timings.Blocked = millis(e.LimiterWait)
+		entry.Time += entry.Timings.Blocked
+	}
+	return entry
+}
+
+// newRequest 转换请求，请求体按实际发送的内容记录，敏感字段的值已隐藏
+func newRequest(e client.Exchange) Request {
+	req := e.Request
+	out := Request{
+		Method:      req.Method,
+		URL:         req.URL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []NameValue{},
+		Headers:     headerList(client.RedactHeaders(req.Header)),
+		QueryString: []NameValue{},
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+	if raw := req.RawRequest; raw != nil {
+		out.URL = raw.URL.String()
+		out.HTTPVersion = raw.Proto
+		out.Headers = headerList(client.RedactHeaders(raw.Header))
+		out.QueryString = queryList(raw.URL.Query())
+	}
+	if body, ok := client.RequestBody(req); ok {
+		out.PostData = &PostData{MimeType: req.Header.Get("Content-Type"), Text: string(client.RedactBody([]byte(body)))}
+		out.BodySize = len(body)
+	}
+	return out
+}
+
+// newResponse 转换响应，未收到响应时返回 status 为0的空响应
+func newResponse(e client.Exchange) Response {
+	out := Response{
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []NameValue{},
+		Headers:     []NameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	if e.Response == nil || e.Response.RawResponse == nil {
+		return out
+	}
+
+	resp := e.Response
+	body := resp.Body()
+	out.Status = resp.StatusCode()
+	out.StatusText = http.StatusText(resp.StatusCode())
+	out.HTTPVersion = resp.Proto()
+	out.Headers = headerList(client.RedactHeaders(resp.Header()))
+	out.Content = Content{Size: len(body), MimeType: resp.Header().Get("Content-Type"), Text: string(body)}
+	out.RedirectURL = resp.Header().Get("Location")
+	out.BodySize = len(body)
+	return out
+}
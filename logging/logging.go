@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go-testify-allure-api-test/config"
+)
+
+const (
+	outputConsole = "console"
+	outputFile    = "file"
+	formatText    = "text"
+)
+
+var (
+	defaultLogger *slog.Logger
+	defaultOnce   sync.Once
+)
+
+// New 按 logging.level、logging.format 和 logging.output 创建日志记录器。
+// 输出到文件时返回的 close 函数负责关闭文件，输出到控制台时 close 为空操作
+func New(cfg *config.Config) (*slog.Logger, func() error, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Logging.Level)); err != nil {
+		return nil, nil, fmt.Errorf("logging.level: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	closeFn := func() error { return nil }
+	if cfg.Logging.Output == outputFile {
+		f, err := openFile(cfg.Logging.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		w = f
+		closeFn = f.Close
+	}
+
+	return slog.New(newHandler(w, cfg.Logging.Format, level)), closeFn, nil
+}
+
+// Default 返回按全局配置创建的日志记录器，在首次调用时创建。
+// 创建失败时退回到标准错误输出的文本日志，不影响测试运行
+func Default() *slog.Logger {
+	defaultOnce.Do(func() {
+		logger, _, err := New(config.GetConfig())
+		if err != nil {
+			logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+			logger.Warn("create logger from config failed, falling back to stderr", "error", err)
+		}
+		defaultLogger = logger
+	})
+	return defaultLogger
+}
+
+// newHandler 根据格式创建 JSON 或文本处理器
+func newHandler(w io.Writer, format string, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == formatText {
+		return slog.NewTextHandler(w, opts)
+	}
+	return slog.NewJSONHandler(w, opts)
+}
+
+// openFile 以追加方式打开日志文件，必要时创建上级目录
+func openFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open log file: %w", err)
+	}
+	return f, nil
+}
@@ -2,26 +2,39 @@ package models
 
 import "time"
 
+// DateLayout 查询参数中使用的日期格式
+const DateLayout = "2006-01-02"
+
 // Product 商品模型
 type Product struct {
-	ID          int     `json:"id"`
-	Title       string  `json:"title"`
-	Price       float64 `json:"price"`
-	Description string  `json:"description"`
-	Category    string  `json:"category"`
-	Image       string  `json:"image"`
-	Rating      Rating  `json:"rating"`
+	ID          int     `json:"id" jsonschema:"required,minimum=1"`
+	Title       string  `json:"title" jsonschema:"required,minLength=1"`
+	Price       float64 `json:"price" jsonschema:"required,minimum=0"`
+	Description string  `json:"description" jsonschema:"required"`
+	Category    string  `json:"category" jsonschema:"required,minLength=1"`
+	Image       string  `json:"image" jsonschema:"required,format=uri"`
+	Rating      Rating  `json:"rating" jsonschema:"required"`
 }
 
 // Rating 评分模型
 type Rating struct {
-	Rate  float64 `json:"rate"`
-	Count int     `json:"count"`
+	Rate  float64 `json:"rate" jsonschema:"required,minimum=0,maximum=5"`
+	Count int     `json:"count" jsonschema:"required,minimum=0"`
 }
 
 // User 用户模型
 type User struct {
-	ID       int     `json:"id"`
+	ID       int     `json:"id" jsonschema:"required,minimum=1"`
+	Email    string  `json:"email" jsonschema:"required,format=email"`
+	Username string  `json:"username" jsonschema:"required,minLength=1"`
+	Password string  `json:"password" jsonschema:"required"`
+	Name     Name    `json:"name" jsonschema:"required"`
+	Address  Address `json:"address" jsonschema:"required"`
+	Phone    string  `json:"phone" jsonschema:"required"`
+}
+
+// CreateUserRequest 创建用户请求模型
+type CreateUserRequest struct {
 	Email    string  `json:"email"`
 	Username string  `json:"username"`
 	Password string  `json:"password"`
@@ -30,39 +43,63 @@ type User struct {
 	Phone    string  `json:"phone"`
 }
 
+// UpdateUserRequest 更新用户请求模型
+type UpdateUserRequest struct {
+	Email    string   `json:"email,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	Name     *Name    `json:"name,omitempty"`
+	Address  *Address `json:"address,omitempty"`
+	Phone    string   `json:"phone,omitempty"`
+}
+
 // Name 姓名模型
 type Name struct {
-	Firstname string `json:"firstname"`
-	Lastname  string `json:"lastname"`
+	Firstname string `json:"firstname" jsonschema:"required"`
+	Lastname  string `json:"lastname" jsonschema:"required"`
 }
 
 // Address 地址模型
 type Address struct {
-	City     string   `json:"city"`
-	Street   string   `json:"street"`
-	Number   int      `json:"number"`
-	Zipcode  string   `json:"zipcode"`
-	Geolocation Geolocation `json:"geolocation"`
+	City        string      `json:"city" jsonschema:"required"`
+	Street      string      `json:"street" jsonschema:"required"`
+	Number      int         `json:"number" jsonschema:"required,minimum=0"`
+	Zipcode     string      `json:"zipcode" jsonschema:"required"`
+	Geolocation Geolocation `json:"geolocation" jsonschema:"required"`
 }
 
 // Geolocation 地理位置模型
 type Geolocation struct {
-	Lat  string `json:"lat"`
-	Long string `json:"long"`
+	Lat  string `json:"lat" jsonschema:"required"`
+	Long string `json:"long" jsonschema:"required"`
 }
 
 // Cart 购物车模型
 type Cart struct {
-	ID       int        `json:"id"`
-	UserID   int        `json:"userId"`
-	Date     time.Time  `json:"date"`
+	ID       int           `json:"id" jsonschema:"required,minimum=1"`
+	UserID   int           `json:"userId" jsonschema:"required,minimum=1"`
+	Date     time.Time     `json:"date" jsonschema:"required,format=date-time"`
+	Products []CartProduct `json:"products" jsonschema:"required"`
+}
+
+// CreateCartRequest 创建购物车请求模型
+type CreateCartRequest struct {
+	UserID   int           `json:"userId"`
+	Date     time.Time     `json:"date"`
 	Products []CartProduct `json:"products"`
 }
 
+// UpdateCartRequest 更新购物车请求模型
+type UpdateCartRequest struct {
+	UserID   int           `json:"userId,omitempty"`
+	Date     *time.Time    `json:"date,omitempty"`
+	Products []CartProduct `json:"products,omitempty"`
+}
+
 // CartProduct 购物车商品模型
 type CartProduct struct {
-	ProductID int `json:"productId"`
-	Quantity  int `json:"quantity"`
+	ProductID int `json:"productId" jsonschema:"required,minimum=1"`
+	Quantity  int `json:"quantity" jsonschema:"required,minimum=1"`
 }
 
 // LoginRequest 登录请求模型
@@ -73,7 +110,7 @@ type LoginRequest struct {
 
 // LoginResponse 登录响应模型
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token string `json:"token" jsonschema:"required,minLength=1"`
 }
 
 // ErrorResponse 错误响应模型
@@ -98,4 +135,4 @@ type UpdateProductRequest struct {
 	Description string  `json:"description,omitempty"`
 	Image       string  `json:"image,omitempty"`
 	Category    string  `json:"category,omitempty"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// Checker 按契约校验经过其 Transport 的请求和响应，并汇总违规。可在多个 goroutine 中并发使用
+type Checker struct {
+	doc *Document
+
+	mu         sync.Mutex
+	checked    int            // 校验过的请求数
+	operations map[string]int // 每个操作被请求的次数，键形如 "GET /products/{id}"
+	violations []Violation
+}
+
+// NewChecker 创建按 doc 校验的 Checker
+func NewChecker(doc *Document) *Checker {
+	return &Checker{doc: doc, operations: make(map[string]int)}
+}
+
+// Transport 返回在发送前校验请求、收到后校验响应的 http.RoundTripper，next 为nil时使用 http.DefaultTransport。
+// 违规只记录不拦截，请求照常发送
+func (c *Checker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{next: next, checker: c}
+}
+
+// Violations 返回全部违规，按发生顺序排列
+func (c *Checker) Violations() []Violation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Violation(nil), c.violations...)
+}
+
+// Failures 返回应该使用例失败的违规，即除 Expected 之外的违规
+func (c *Checker) Failures() []Violation {
+	var failures []Violation
+	for _, v := range c.Violations() {
+		if !v.Expected() {
+			failures = append(failures, v)
+		}
+	}
+	return failures
+}
+
+// Checked 返回校验过的请求数
+func (c *Checker) Checked() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.checked
+}
+
+// Merge 把 other 的校验记录并入 c，用于把各用例的结果汇总为整次运行的结果
+func (c *Checker) Merge(other *Checker) {
+	other.mu.Lock()
+	checked := other.checked
+	operations := make(map[string]int, len(other.operations))
+	for op, n := range other.operations {
+		operations[op] = n
+	}
+	violations := append([]Violation(nil), other.violations...)
+	other.mu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checked += checked
+	for op, n := range operations {
+		c.operations[op] += n
+	}
+	c.violations = append(c.violations, violations...)
+}
+
+// Summary 返回文本格式的汇总：校验的请求数、每个操作的请求数和违规数、未覆盖的操作以及全部违规
+func (c *Checker) Summary() string {
+	c.mu.Lock()
+	checked := c.checked
+	requested := make(map[string]int, len(c.operations))
+	for op, n := range c.operations {
+		requested[op] = n
+	}
+	violations := append([]Violation(nil), c.violations...)
+	c.mu.Unlock()
+
+	failed, expected := 0, 0
+	perOperation := make(map[string]int)
+	for _, v := range violations {
+		if v.Expected() {
+			expected++
+		} else {
+			failed++
+		}
+		op := v.Operation
+		if op == "" {
+			op = v.Method + " " + v.Path + "（未声明）"
+		}
+		perOperation[op]++
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "OpenAPI: %s %s\n", c.doc.Info.Title, c.doc.Info.Version)
+	fmt.Fprintf(&b, "校验请求: %d，违规: %d，其中预期的请求违规（服务端返回4xx）: %d\n\n", checked, failed+expected, expected)
+
+	var uncovered []string
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OPERATION\tREQUESTS\tVIOLATIONS")
+	for _, op := range c.doc.Operations() {
+		if requested[op] == 0 {
+			uncovered = append(uncovered, op)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\n", op, requested[op], perOperation[op])
+		delete(perOperation, op)
+	}
+	for _, op := range sortedKeys(perOperation) {
+		fmt.Fprintf(w, "%s\t-\t%d\n", op, perOperation[op])
+	}
+	w.Flush()
+
+	if len(uncovered) > 0 {
+		fmt.Fprintf(&b, "\n未覆盖的操作 (%d):\n  %s\n", len(uncovered), strings.Join(uncovered, "\n  "))
+	}
+	if len(violations) > 0 {
+		sort.SliceStable(violations, func(i, j int) bool {
+			return !violations[i].Expected() && violations[j].Expected()
+		})
+		b.WriteString("\n违规:\n")
+		for _, v := range violations {
+			mark := "✗"
+			if v.Expected() {
+				mark = "·"
+			}
+			fmt.Fprintf(&b, "%s %s\n", mark, v)
+		}
+	}
+	return b.String()
+}
+
+// record 记录一次请求的校验结果
+func (c *Checker) record(req *http.Request, violations []Violation) {
+	op := ""
+	if template, item, _ := c.doc.find(req.URL.Path); item != nil {
+		if _, ok := item.operations()[req.Method]; ok {
+			op = req.Method + " " + template
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checked++
+	if op != "" {
+		c.operations[op]++
+	}
+	c.violations = append(c.violations, violations...)
+}
+
+// transport 校验请求和响应的 http.RoundTripper
+type transport struct {
+	next    http.RoundTripper
+	checker *Checker
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	violations := t.checker.doc.ValidateRequest(req, body)
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.checker.record(req, violations)
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.checker.record(req, violations)
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// 请求违规带上响应状态码，用于区分被服务端拒绝的反向用例
+	for i := range violations {
+		violations[i].Status = resp.StatusCode
+	}
+	violations = append(violations, t.checker.doc.ValidateResponse(req, resp, respBody)...)
+	t.checker.record(req, violations)
+	return resp, nil
+}
@@ -0,0 +1,383 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"go-testify-allure-api-test/schema"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document OpenAPI 3 文档中校验需要的部分，其余字段被忽略
+type Document struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Info       Info                 `yaml:"info"`
+	Servers    []Server             `yaml:"servers"`
+	Paths      map[string]*PathItem `yaml:"paths"`
+	Components Components           `yaml:"components"`
+
+	basePath string
+	routes   []route
+}
+
+// Info 文档信息
+type Info struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+// Server 服务地址，第一个地址的路径作为所有路径的前缀
+type Server struct {
+	URL string `yaml:"url"`
+}
+
+// Components 可通过 $ref 引用的定义
+type Components struct {
+	Schemas    map[string]*schema.Schema `yaml:"schemas"`
+	Parameters map[string]*Parameter     `yaml:"parameters"`
+	Responses  map[string]*Response      `yaml:"responses"`
+}
+
+// PathItem 一个路径上的操作
+type PathItem struct {
+	Parameters []*Parameter `yaml:"parameters"`
+	Get        *Operation   `yaml:"get"`
+	Put        *Operation   `yaml:"put"`
+	Post       *Operation   `yaml:"post"`
+	Delete     *Operation   `yaml:"delete"`
+	Patch      *Operation   `yaml:"patch"`
+	Head       *Operation   `yaml:"head"`
+	Options    *Operation   `yaml:"options"`
+}
+
+// Operation 一个操作
+type Operation struct {
+	OperationID string               `yaml:"operationId"`
+	Parameters  []*Parameter         `yaml:"parameters"`
+	RequestBody *RequestBody         `yaml:"requestBody"`
+	Responses   map[string]*Response `yaml:"responses"`
+}
+
+// Parameter 路径、查询或请求头参数
+type Parameter struct {
+	Ref      string         `yaml:"$ref"`
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"` // path、query 或 header
+	Required bool           `yaml:"required"`
+	Schema   *schema.Schema `yaml:"schema"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Required bool                  `yaml:"required"`
+	Content  map[string]*MediaType `yaml:"content"`
+}
+
+// Response 一个状态码的响应
+type Response struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Content     map[string]*MediaType `yaml:"content"`
+}
+
+// MediaType 某种内容类型的请求体或响应体
+type MediaType struct {
+	Schema *schema.Schema `yaml:"schema"`
+}
+
+// route 一个路径模板及其操作，按段拆分后用于匹配请求路径
+type route struct {
+	template string
+	segments []string
+	item     *PathItem
+}
+
+// Load 读取 YAML 或 JSON 格式的 OpenAPI 3 文档，并检查其中的 $ref 都能解析
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Parse 解析 YAML 或 JSON 格式的 OpenAPI 3 文档
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("不支持的 OpenAPI 版本 %q，需要 3.x", doc.OpenAPI)
+	}
+	if len(doc.Servers) > 0 {
+		u, err := url.Parse(doc.Servers[0].URL)
+		if err != nil {
+			return nil, fmt.Errorf("servers[0].url: %w", err)
+		}
+		doc.basePath = strings.TrimSuffix(u.Path, "/")
+	}
+
+	for template, item := range doc.Paths {
+		if !strings.HasPrefix(template, "/") {
+			return nil, fmt.Errorf("路径 %q 必须以 / 开头", template)
+		}
+		doc.routes = append(doc.routes, route{template: template, segments: splitPath(template), item: item})
+	}
+	// 字面量段多的路径优先，如 /products/categories 优先于 /products/{id}
+	sort.Slice(doc.routes, func(i, j int) bool {
+		a, b := literals(doc.routes[i].segments), literals(doc.routes[j].segments)
+		if a != b {
+			return a > b
+		}
+		return doc.routes[i].template < doc.routes[j].template
+	})
+
+	if err := doc.checkRefs(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Operations 返回文档中所有操作，形如 "GET /products/{id}"，按路径排序
+func (d *Document) Operations() []string {
+	var ops []string
+	for template, item := range d.Paths {
+		for method := range item.operations() {
+			ops = append(ops, method+" "+template)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		mi, pi, _ := strings.Cut(ops[i], " ")
+		mj, pj, _ := strings.Cut(ops[j], " ")
+		if pi != pj {
+			return pi < pj
+		}
+		return mi < mj
+	})
+	return ops
+}
+
+// find 查找请求路径对应的路径模板，返回模板、操作所在的 PathItem 和路径参数；
+// 没有匹配的路径时 item 为nil
+func (d *Document) find(path string) (template string, item *PathItem, params map[string]string) {
+	if d.basePath != "" {
+		trimmed, ok := strings.CutPrefix(path, d.basePath)
+		if !ok {
+			return "", nil, nil
+		}
+		path = trimmed
+	}
+	segments := splitPath(path)
+	for _, r := range d.routes {
+		if params, ok := match(r.segments, segments); ok {
+			return r.template, r.item, params
+		}
+	}
+	return "", nil, nil
+}
+
+// resolveSchema 解析 "#/components/schemas/名称" 形式的引用，实现 schema.Resolver
+func (d *Document) resolveSchema(ref string) (*schema.Schema, bool) {
+	name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+	if !ok {
+		return nil, false
+	}
+	s, ok := d.Components.Schemas[name]
+	return s, ok && s != nil
+}
+
+// parameter 解析参数引用，无法解析时返回nil
+func (d *Document) parameter(p *Parameter) *Parameter {
+	if p == nil || p.Ref == "" {
+		return p
+	}
+	name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	if !ok {
+		return nil
+	}
+	return d.Components.Parameters[name]
+}
+
+// response 解析响应引用，无法解析时返回nil
+func (d *Document) response(r *Response) *Response {
+	if r == nil || r.Ref == "" {
+		return r
+	}
+	name, ok := strings.CutPrefix(r.Ref, "#/components/responses/")
+	if !ok {
+		return nil
+	}
+	return d.Components.Responses[name]
+}
+
+// parameters 返回操作的全部参数，操作中的参数覆盖路径上同名同位置的参数
+func (d *Document) parameters(item *PathItem, op *Operation) []*Parameter {
+	var params []*Parameter
+	seen := make(map[string]bool)
+	for _, list := range [][]*Parameter{op.Parameters, item.Parameters} {
+		for _, p := range list {
+			p = d.parameter(p)
+			if p == nil || seen[p.In+":"+p.Name] {
+				continue
+			}
+			seen[p.In+":"+p.Name] = true
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// checkRefs 检查文档中所有的 $ref 都能解析
+func (d *Document) checkRefs() error {
+	var problems []string
+	var walk func(s *schema.Schema, at string)
+	walk = func(s *schema.Schema, at string) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			if _, ok := d.resolveSchema(s.Ref); !ok {
+				problems = append(problems, fmt.Sprintf("%s: 无法解析 %s", at, s.Ref))
+			}
+			return
+		}
+		walk(s.Items, at+"/items")
+		for name, prop := range s.Properties {
+			walk(prop, at+"/properties/"+name)
+		}
+	}
+
+	for name, s := range d.Components.Schemas {
+		walk(s, "#/components/schemas/"+name)
+	}
+	for name, p := range d.Components.Parameters {
+		walk(p.Schema, "#/components/parameters/"+name)
+	}
+	for name, r := range d.Components.Responses {
+		for mediaType, m := range r.Content {
+			walk(m.Schema, "#/components/responses/"+name+"/"+mediaType)
+		}
+	}
+	for template, item := range d.Paths {
+		at := "#/paths/" + template
+		for _, p := range item.Parameters {
+			if d.parameter(p) == nil {
+				problems = append(problems, fmt.Sprintf("%s: 无法解析 %s", at, p.Ref))
+			}
+		}
+		for method, op := range item.operations() {
+			at := at + "/" + strings.ToLower(method)
+			for _, p := range op.Parameters {
+				if resolved := d.parameter(p); resolved == nil {
+					problems = append(problems, fmt.Sprintf("%s: 无法解析 %s", at, p.Ref))
+				} else if p.Ref == "" {
+					walk(p.Schema, at+"/parameters/"+p.Name)
+				}
+			}
+			if op.RequestBody != nil {
+				for mediaType, m := range op.RequestBody.Content {
+					walk(m.Schema, at+"/requestBody/"+mediaType)
+				}
+			}
+			for status, resp := range op.Responses {
+				if resp != nil && resp.Ref != "" {
+					if d.response(resp) == nil {
+						problems = append(problems, fmt.Sprintf("%s/responses/%s: 无法解析 %s", at, status, resp.Ref))
+					}
+					continue
+				}
+				if resp == nil {
+					continue
+				}
+				for mediaType, m := range resp.Content {
+					walk(m.Schema, at+"/responses/"+status+"/"+mediaType)
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("文档中有无法解析的引用:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// operations 返回路径上定义的操作，键为大写的请求方法
+func (item *PathItem) operations() map[string]*Operation {
+	ops := make(map[string]*Operation)
+	for method, op := range map[string]*Operation{
+		http.MethodGet:     item.Get,
+		http.MethodPut:     item.Put,
+		http.MethodPost:    item.Post,
+		http.MethodDelete:  item.Delete,
+		http.MethodPatch:   item.Patch,
+		http.MethodHead:    item.Head,
+		http.MethodOptions: item.Options,
+	} {
+		if op != nil {
+			ops[method] = op
+		}
+	}
+	return ops
+}
+
+// splitPath 按 / 拆分路径，忽略首尾的 /
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// literals 返回不是 {参数} 的段数
+func literals(segments []string) int {
+	n := 0
+	for _, s := range segments {
+		if !isParam(s) {
+			n++
+		}
+	}
+	return n
+}
+
+// isParam 判断路径模板的段是否为 {参数}
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// match 按路径模板匹配请求路径，返回路径参数
+func match(template, path []string) (map[string]string, bool) {
+	if len(template) != len(path) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, segment := range template {
+		if isParam(segment) {
+			if path[i] == "" {
+				return nil, false
+			}
+			value, err := url.PathUnescape(path[i])
+			if err != nil {
+				value = path[i]
+			}
+			params[strings.Trim(segment, "{}")] = value
+			continue
+		}
+		if segment != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
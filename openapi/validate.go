@@ -0,0 +1,290 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-testify-allure-api-test/schema"
+)
+
+// 违规发生的阶段
+const (
+	PhaseRequest  = "request"
+	PhaseResponse = "response"
+)
+
+// Violation 一处不符合契约的请求或响应
+type Violation struct {
+	Phase     string `json:"phase"`               // request 或 response
+	Method    string `json:"method"`              // 请求方法
+	Path      string `json:"path"`                // 实际请求的路径和查询参数
+	Operation string `json:"operation,omitempty"` // 匹配的操作，如 "GET /products/{id}"，未匹配时为空
+	Status    int    `json:"status,omitempty"`    // 响应状态码，未收到响应时为0
+	Location  string `json:"location"`            // 出错的位置：path、method、query.limit、header.X、content-type、status、body
+	Pointer   string `json:"pointer,omitempty"`   // Location 为 body 时的 JSON Pointer
+	Message   string `json:"message"`
+}
+
+// String 返回 "GET /products/1 → 200 response body/rating/rate: 5.5 大于最大值 5" 形式的描述
+func (v Violation) String() string {
+	status := "-"
+	if v.Status > 0 {
+		status = strconv.Itoa(v.Status)
+	}
+	return fmt.Sprintf("%s %s → %s %s %s%s: %s", v.Method, v.Path, status, v.Phase, v.Location, v.Pointer, v.Message)
+}
+
+// Expected 判断违规是否为预期的：请求不符合契约且服务端以4xx拒绝，通常是有意发送无效请求的反向用例
+func (v Violation) Expected() bool {
+	return v.Phase == PhaseRequest && v.Status >= 400 && v.Status < 500
+}
+
+// ValidateRequest 校验请求的路径、方法、参数和请求体，body 为请求体
+func (d *Document) ValidateRequest(req *http.Request, body []byte) []Violation {
+	c := d.newCheck(req, PhaseRequest)
+	item, op := c.operation(true)
+	if op == nil {
+		return c.violations
+	}
+
+	for _, p := range d.parameters(item, op) {
+		var values []string
+		switch p.In {
+		case "path":
+			if value, ok := c.params[p.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = req.URL.Query()[p.Name]
+		case "header":
+			values = req.Header.Values(p.Name)
+		default:
+			continue
+		}
+		location := p.In + "." + p.Name
+		if len(values) == 0 {
+			if p.Required {
+				c.fail(location, "", "缺少必填参数")
+			}
+			continue
+		}
+		c.validateParam(p, values, location)
+	}
+
+	if op.RequestBody == nil {
+		return c.violations
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			c.fail("body", "", "缺少请求体")
+		}
+		return c.violations
+	}
+	c.validateBody(op.RequestBody.Content, req.Header.Get("Content-Type"), body)
+	return c.violations
+}
+
+// ValidateResponse 校验响应的状态码、内容类型和响应体，body 为响应体。
+// 路径或方法未在契约中声明时由 ValidateRequest 报告，这里不再重复
+func (d *Document) ValidateResponse(req *http.Request, resp *http.Response, body []byte) []Violation {
+	c := d.newCheck(req, PhaseResponse)
+	c.status = resp.StatusCode
+	_, op := c.operation(false)
+	if op == nil {
+		return c.violations
+	}
+
+	declared := d.response(lookupResponse(op.Responses, resp.StatusCode))
+	if declared == nil {
+		c.fail("status", "", fmt.Sprintf("状态码 %d 未在契约中声明，已声明 %s", resp.StatusCode, strings.Join(sortedKeys(op.Responses), ", ")))
+		return c.violations
+	}
+	if len(declared.Content) == 0 || req.Method == http.MethodHead {
+		return c.violations
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		c.fail("body", "", "响应体为空")
+		return c.violations
+	}
+	c.validateBody(declared.Content, resp.Header.Get("Content-Type"), body)
+	return c.violations
+}
+
+// check 一次请求或响应校验的状态
+type check struct {
+	doc        *Document
+	req        *http.Request
+	phase      string
+	status     int
+	template   string
+	params     map[string]string
+	violations []Violation
+}
+
+// newCheck 创建校验 req 的 check
+func (d *Document) newCheck(req *http.Request, phase string) *check {
+	return &check{doc: d, req: req, phase: phase}
+}
+
+// fail 记录一处违规
+func (c *check) fail(location, pointer, message string) {
+	path := c.req.URL.Path
+	if c.req.URL.RawQuery != "" {
+		path += "?" + c.req.URL.RawQuery
+	}
+	v := Violation{
+		Phase:    c.phase,
+		Method:   c.req.Method,
+		Path:     path,
+		Status:   c.status,
+		Location: location,
+		Pointer:  pointer,
+		Message:  message,
+	}
+	if c.template != "" {
+		v.Operation = c.req.Method + " " + c.template
+	}
+	c.violations = append(c.violations, v)
+}
+
+// operation 查找请求对应的操作，路径或方法未声明时返回nil，report 为 true 时记录违规
+func (c *check) operation(report bool) (*PathItem, *Operation) {
+	template, item, params := c.doc.find(c.req.URL.Path)
+	if item == nil {
+		if report {
+			c.fail("path", "", "路径未在契约中声明")
+		}
+		return nil, nil
+	}
+	c.params = params
+	ops := item.operations()
+	op := ops[c.req.Method]
+	if op == nil {
+		if !report {
+			return nil, nil
+		}
+		methods := make([]string, 0, len(ops))
+		for method := range ops {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		c.fail("method", "", fmt.Sprintf("%s 未声明 %s 方法，已声明 %s", template, c.req.Method, strings.Join(methods, ", ")))
+		return nil, nil
+	}
+	c.template = template
+	return item, op
+}
+
+// validateParam 按参数的 Schema 校验参数值，数组参数校验每个值
+func (c *check) validateParam(p *Parameter, values []string, location string) {
+	if p.Schema == nil {
+		return
+	}
+	s := p.Schema
+	if s.Ref != "" {
+		if resolved, ok := c.doc.resolveSchema(s.Ref); ok {
+			s = resolved
+		}
+	}
+
+	var value interface{}
+	if s.Type == "array" {
+		var items []interface{}
+		for _, v := range values {
+			for _, item := range strings.Split(v, ",") {
+				items = append(items, paramValue(s.Items, item))
+			}
+		}
+		value = items
+	} else {
+		value = paramValue(s, values[0])
+	}
+	for _, v := range s.ValidateValue(value, c.doc.resolveSchema) {
+		c.fail(location, v.Pointer, v.Message)
+	}
+}
+
+// paramValue 按 Schema 的类型转换参数值，无法转换时保留字符串，由校验报告类型错误
+func paramValue(s *schema.Schema, value string) interface{} {
+	if s == nil {
+		return value
+	}
+	switch s.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// validateBody 检查内容类型已声明，JSON 内容按 Schema 校验
+func (c *check) validateBody(content map[string]*MediaType, contentType string, body []byte) {
+	mediaType, declared := matchMediaType(content, contentType)
+	if declared == nil {
+		c.fail("content-type", "", fmt.Sprintf("内容类型 %q 未在契约中声明，已声明 %s", contentType, strings.Join(sortedKeys(content), ", ")))
+		return
+	}
+	if declared.Schema == nil || !isJSON(mediaType) {
+		return
+	}
+	violations, err := declared.Schema.ValidateWith(body, c.doc.resolveSchema)
+	if err != nil {
+		c.fail("body", "", err.Error())
+		return
+	}
+	for _, v := range violations {
+		c.fail("body", v.Pointer, v.Message)
+	}
+}
+
+// matchMediaType 按 Content-Type 查找声明的内容类型，支持 application/* 和 */* 通配
+func matchMediaType(content map[string]*MediaType, contentType string) (string, *MediaType) {
+	actual, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", nil
+	}
+	major, _, _ := strings.Cut(actual, "/")
+	for _, candidate := range []string{actual, major + "/*", "*/*"} {
+		if m, ok := content[candidate]; ok && m != nil {
+			return actual, m
+		}
+	}
+	return "", nil
+}
+
+// isJSON 判断媒体类型是否为 JSON，包括 application/problem+json 等
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// lookupResponse 按状态码查找响应：先精确匹配，再匹配 2XX 形式的范围，最后使用 default
+func lookupResponse(responses map[string]*Response, status int) *Response {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if resp, ok := responses[key]; ok && resp != nil {
+			return resp
+		}
+	}
+	return nil
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,235 @@
+package preflight
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go-testify-allure-api-test/config"
+)
+
+// 检查项名称
+const (
+	CheckURL  = "url"
+	CheckDNS  = "dns"
+	CheckTCP  = "tcp"
+	CheckTLS  = "tls"
+	CheckHTTP = "http"
+)
+
+// Options 预检参数
+type Options struct {
+	BaseURL     string
+	Endpoints   []string      // 相对 BaseURL 的路径，需要返回2xx
+	ContentType string        // 期望的响应媒体类型，为空不检查
+	Timeout     time.Duration // 每项检查的超时时间
+}
+
+// FromConfig 根据 api.base_url 和 preflight 配置创建预检参数
+func FromConfig(cfg *config.Config) Options {
+	return Options{
+		BaseURL:     cfg.API.BaseURL,
+		Endpoints:   cfg.Preflight.Endpoints,
+		ContentType: cfg.Preflight.ContentType,
+		Timeout:     cfg.Preflight.Timeout,
+	}
+}
+
+// Check 单项检查的结果
+type Check struct {
+	Name     string
+	Target   string
+	Duration time.Duration
+	Detail   string // 成功时的补充信息，如解析到的地址、证书有效期、响应状态
+	Err      error
+}
+
+// Report 预检报告，检查按执行顺序排列，第一项失败后不再继续
+type Report struct {
+	BaseURL string
+	Started time.Time
+	Checks  []Check
+}
+
+// Run 依次检查 BaseURL 的 DNS 解析、TCP 连接、TLS 握手（仅 https）和每个接口的 HTTP 响应
+func Run(ctx context.Context, opts Options) Report {
+	r := Report{BaseURL: opts.BaseURL, Started: time.Now()}
+
+	u, err := url.Parse(opts.BaseURL)
+	if err == nil && (u.Scheme != "http" && u.Scheme != "https" || u.Host == "") {
+		err = errors.New("需要 http 或 https 的绝对地址")
+	}
+	if !r.add(CheckURL, opts.BaseURL, time.Now(), "", err) {
+		return r
+	}
+
+	host := u.Hostname()
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	addr := net.JoinHostPort(host, port)
+
+	if net.ParseIP(host) == nil {
+		start := time.Now()
+		lookupCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		addrs, err := net.DefaultResolver.LookupHost(lookupCtx, host)
+		cancel()
+		if !r.add(CheckDNS, host, start, strings.Join(addrs, ", "), err) {
+			return r
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	detail := ""
+	if err == nil {
+		detail = conn.RemoteAddr().String()
+		conn.Close()
+	}
+	if !r.add(CheckTCP, addr, start, detail, err) {
+		return r
+	}
+
+	if u.Scheme == "https" {
+		start := time.Now()
+		detail, err := checkTLS(ctx, dialer, addr, host)
+		if !r.add(CheckTLS, addr, start, detail, err) {
+			return r
+		}
+	}
+
+	httpClient := &http.Client{Timeout: opts.Timeout}
+	base := strings.TrimRight(opts.BaseURL, "/")
+	for _, endpoint := range opts.Endpoints {
+		target := base + endpoint
+		start := time.Now()
+		detail, err := checkHTTP(ctx, httpClient, target, opts.ContentType)
+		if !r.add(CheckHTTP, "GET "+target, start, detail, err) {
+			return r
+		}
+	}
+	return r
+}
+
+// add 记录一项检查，返回检查是否通过
+func (r *Report) add(name, target string, start time.Time, detail string, err error) bool {
+	r.Checks = append(r.Checks, Check{
+		Name:     name,
+		Target:   target,
+		Duration: time.Since(start),
+		Detail:   detail,
+		Err:      err,
+	})
+	return err == nil
+}
+
+// checkTLS 完成 TLS 握手并校验证书，返回协议版本和证书有效期
+func checkTLS(ctx context.Context, dialer *net.Dialer, addr, host string) (string, error) {
+	tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}
+	conn, err := tlsDialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	detail := tls.VersionName(state.Version)
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		detail += fmt.Sprintf(", 证书 %s 有效期至 %s", cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+	}
+	return detail, nil
+}
+
+// checkHTTP 发送 GET 请求，要求2xx响应且媒体类型与 contentType 一致
+func checkHTTP(ctx context.Context, httpClient *http.Client, target, contentType string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "*/*")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	n, _ := io.Copy(io.Discard, resp.Body)
+
+	got := resp.Header.Get("Content-Type")
+	detail := fmt.Sprintf("%s, %s, %d bytes", resp.Status, got, n)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return detail, fmt.Errorf("响应状态 %s，期望2xx", resp.Status)
+	}
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(got)
+		if err != nil || !strings.EqualFold(mediaType, contentType) {
+			return detail, fmt.Errorf("响应类型 %q，期望 %s", got, contentType)
+		}
+	}
+	return detail, nil
+}
+
+// OK 所有检查是否通过
+func (r Report) OK() bool {
+	return r.Failed() == nil
+}
+
+// Failed 返回第一项失败的检查，全部通过时返回nil
+func (r Report) Failed() *Check {
+	for i := range r.Checks {
+		if r.Checks[i].Err != nil {
+			return &r.Checks[i]
+		}
+	}
+	return nil
+}
+
+// Reason 用一行文字说明失败原因，全部通过时返回空字符串
+func (r Report) Reason() string {
+	c := r.Failed()
+	if c == nil {
+		return ""
+	}
+	return fmt.Sprintf("预检失败 [%s] %s: %v", c.Name, c.Target, c.Err)
+}
+
+// String 返回诊断报告，每项检查占一行
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Pre-flight check: %s\n", r.BaseURL)
+	fmt.Fprintf(&b, "Started: %s\n\n", r.Started.Format(time.RFC3339))
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, c := range r.Checks {
+		status, detail := "OK", c.Detail
+		if detail == "" {
+			detail = "-"
+		}
+		if c.Err != nil {
+			status, detail = "FAIL", c.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", status, c.Name, c.Target, c.Duration.Round(time.Millisecond), detail)
+	}
+	w.Flush()
+
+	if reason := r.Reason(); reason != "" {
+		fmt.Fprintf(&b, "\nResult: %s\n", reason)
+	} else {
+		b.WriteString("\nResult: OK\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,207 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-testify-allure-api-test/models"
+)
+
+// Draft 生成的 Schema 所遵循的 JSON Schema 版本
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema JSON Schema 的子集，覆盖模型校验需要的关键字，也用于解析 OpenAPI 文档中的 schema
+type Schema struct {
+	Ref         string        `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Schema      string        `json:"$schema,omitempty" yaml:"$schema,omitempty"`
+	Title       string        `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string        `json:"description,omitempty" yaml:"description,omitempty"`
+	Type        string        `json:"type,omitempty" yaml:"type,omitempty"`
+	Nullable    bool          `json:"nullable,omitempty" yaml:"nullable,omitempty"` // OpenAPI 3.0 的扩展，允许 null
+	Format      string        `json:"format,omitempty" yaml:"format,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty" yaml:"enum,omitempty"`
+
+	Minimum   *float64 `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Pattern   string   `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+
+	Items    *Schema `json:"items,omitempty" yaml:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+
+	Defs map[string]*Schema `json:"$defs,omitempty" yaml:"$defs,omitempty"`
+}
+
+// String 返回缩进的 JSON
+func (s *Schema) String() string {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%+v", *s)
+	}
+	return string(data)
+}
+
+// For 生成 v 的类型对应的 Schema，v 通常是模型的零值，如 For(models.Product{})、For([]models.Cart{})。
+// jsonschema 标签无效时 panic，与 regexp.MustCompile 一样属于编程错误
+func For(v interface{}) *Schema {
+	s := generate(reflect.TypeOf(v))
+	s.Schema = Draft
+	return s
+}
+
+// Models 返回 models 包中响应模型的 Schema，键为类型名
+func Models() map[string]*Schema {
+	return map[string]*Schema{
+		"Product":       For(models.Product{}),
+		"Rating":        For(models.Rating{}),
+		"User":          For(models.User{}),
+		"Name":          For(models.Name{}),
+		"Address":       For(models.Address{}),
+		"Geolocation":   For(models.Geolocation{}),
+		"Cart":          For(models.Cart{}),
+		"CartProduct":   For(models.CartProduct{}),
+		"LoginResponse": For(models.LoginResponse{}),
+	}
+}
+
+// timeType time.Time 按 RFC 3339 字符串编码
+var timeType = reflect.TypeOf(time.Time{})
+
+// generate 按类型生成 Schema，嵌套的结构体直接内联
+func generate(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: generate(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return generateStruct(t)
+	}
+	return &Schema{}
+}
+
+// generateStruct 按 json 标签生成对象的属性，jsonschema 标签设置必填和约束
+func generateStruct(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := generate(field.Type)
+		required, err := applyTag(prop, field.Tag.Get("jsonschema"))
+		if err != nil {
+			panic(fmt.Sprintf("schema: %s.%s: %v", t.Name(), field.Name, err))
+		}
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// IsRequired 判断结构体字段的 jsonschema 标签是否声明为必填，与 For 生成的 required 一致，
+// 供 client.CheckDrift 等需要判断必填字段的地方使用。标签无效时按非必填处理
+func IsRequired(field reflect.StructField) bool {
+	required, err := applyTag(&Schema{}, field.Tag.Get("jsonschema"))
+	return err == nil && required
+}
+
+// applyTag 把 jsonschema 标签中的约束写入 s，返回字段是否必填。
+// 标签由逗号分隔，如 "required,minimum=0,maximum=5"、"format=email"、"enum=asc|desc"；
+// pattern 中不能包含逗号
+func applyTag(s *Schema, tag string) (required bool, err error) {
+	if tag == "" {
+		return false, nil
+	}
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "required":
+			required = true
+		case "format":
+			s.Format = value
+		case "pattern":
+			s.Pattern = value
+		case "description":
+			s.Description = value
+		case "enum":
+			for _, v := range strings.Split(value, "|") {
+				s.Enum = append(s.Enum, enumValue(s.Type, v))
+			}
+		case "minimum", "maximum":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return false, fmt.Errorf("%s 不是数字: %q", key, value)
+			}
+			if key == "minimum" {
+				s.Minimum = &f
+			} else {
+				s.Maximum = &f
+			}
+		case "minLength", "maxLength", "minItems", "maxItems":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return false, fmt.Errorf("%s 不是非负整数: %q", key, value)
+			}
+			switch key {
+			case "minLength":
+				s.MinLength = &n
+			case "maxLength":
+				s.MaxLength = &n
+			case "minItems":
+				s.MinItems = &n
+			default:
+				s.MaxItems = &n
+			}
+		default:
+			return false, fmt.Errorf("未知的约束 %q", key)
+		}
+	}
+	return required, nil
+}
+
+// enumValue 按字段类型转换枚举值，数字字段的枚举值为数字
+func enumValue(typ, v string) interface{} {
+	if typ == "integer" || typ == "number" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
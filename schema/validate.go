@@ -0,0 +1,348 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Violation 一处不符合 Schema 的值
+type Violation struct {
+	Pointer string `json:"pointer"` // RFC 6901 JSON Pointer，如 /0/rating/rate，根为空字符串
+	Keyword string `json:"keyword"` // 未满足的关键字，如 required、type、minimum、format
+	Message string `json:"message"`
+}
+
+// String 返回 "/0/rating/rate: 3.5 大于最大值 3" 形式的描述
+func (v Violation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "(root)"
+	}
+	return pointer + ": " + v.Message
+}
+
+// Resolver 根据 $ref 返回引用的 Schema，无法解析时 ok 为 false
+type Resolver func(ref string) (s *Schema, ok bool)
+
+// Validate 校验 JSON 文档，返回全部违规；data 不是合法的 JSON 时返回错误。
+// $ref 按 s 的 $defs 解析，如 "#/$defs/Rating"
+func (s *Schema) Validate(data []byte) ([]Violation, error) {
+	return s.ValidateWith(data, nil)
+}
+
+// ValidateWith 与 Validate 相同，$ref 由 resolve 解析；resolve 为nil时按 s 的 $defs 解析
+func (s *Schema) ValidateWith(data []byte, resolve Resolver) ([]Violation, error) {
+	value, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.ValidateValue(value, resolve), nil
+}
+
+// ValidateValue 校验已解码的 JSON 值。数字应为 json.Number（UseNumber 解码）或 float64
+func (s *Schema) ValidateValue(value interface{}, resolve Resolver) []Violation {
+	if resolve == nil {
+		resolve = s.resolveDefs
+	}
+	v := &validator{resolve: resolve}
+	v.validate(s, value, "")
+	return v.violations
+}
+
+// ValidateResponse 校验响应体，返回全部违规；响应体为空或不是合法的 JSON 时返回错误
+func ValidateResponse(resp *resty.Response, s *Schema) ([]Violation, error) {
+	if resp == nil {
+		return nil, fmt.Errorf("响应为nil")
+	}
+	if len(bytes.TrimSpace(resp.Body())) == 0 {
+		return nil, fmt.Errorf("%s %s 的响应体为空", resp.Request.Method, resp.Request.URL)
+	}
+	return s.Validate(resp.Body())
+}
+
+// decode 按 UseNumber 解码 JSON，保留整数和小数的区别
+func decode(data []byte) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("不是合法的 JSON: %w", err)
+	}
+	return value, nil
+}
+
+// resolveDefs 解析 "#/$defs/名称" 形式的引用
+func (s *Schema) resolveDefs(ref string) (*Schema, bool) {
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return nil, false
+	}
+	def, ok := s.Defs[name]
+	return def, ok
+}
+
+// validator 一次校验的状态
+type validator struct {
+	resolve    Resolver
+	violations []Violation
+	depth      int
+}
+
+// maxRefDepth $ref 的最大嵌套层数，防止循环引用
+const maxRefDepth = 64
+
+// fail 记录一处违规
+func (v *validator) fail(pointer, keyword, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Pointer: pointer, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate 按 s 校验 value，value 位于 pointer
+func (v *validator) validate(s *Schema, value interface{}, pointer string) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		target, ok := v.resolve(s.Ref)
+		if !ok {
+			v.fail(pointer, "$ref", "无法解析引用 %s", s.Ref)
+			return
+		}
+		if v.depth >= maxRefDepth {
+			v.fail(pointer, "$ref", "引用 %s 嵌套过深", s.Ref)
+			return
+		}
+		v.depth++
+		v.validate(target, value, pointer)
+		v.depth--
+		return
+	}
+
+	if value == nil && s.Nullable {
+		return
+	}
+	if s.Type != "" && !hasType(value, s.Type) {
+		v.fail(pointer, "type", "类型应为 %s，实际为 %s", s.Type, typeOf(value))
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		v.fail(pointer, "enum", "%s 不是允许的值 %v", display(value), s.Enum)
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(s, value, pointer)
+	case []interface{}:
+		v.validateArray(s, value, pointer)
+	case string:
+		v.validateString(s, value, pointer)
+	case json.Number, float64:
+		v.validateNumber(s, toFloat(value), pointer)
+	}
+}
+
+// validateObject 校验必填字段、属性和额外字段
+func (v *validator) validateObject(s *Schema, object map[string]interface{}, pointer string) {
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			v.fail(pointer+"/"+escape(name), "required", "缺少必填字段 %q", name)
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		prop, ok := s.Properties[key]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				v.fail(pointer+"/"+escape(key), "additionalProperties", "不允许的字段 %q", key)
+			}
+			continue
+		}
+		v.validate(prop, object[key], pointer+"/"+escape(key))
+	}
+}
+
+// validateArray 校验元素个数和每个元素
+func (v *validator) validateArray(s *Schema, items []interface{}, pointer string) {
+	if s.MinItems != nil && len(items) < *s.MinItems {
+		v.fail(pointer, "minItems", "元素个数 %d 小于 %d", len(items), *s.MinItems)
+	}
+	if s.MaxItems != nil && len(items) > *s.MaxItems {
+		v.fail(pointer, "maxItems", "元素个数 %d 大于 %d", len(items), *s.MaxItems)
+	}
+	for i, item := range items {
+		v.validate(s.Items, item, pointer+"/"+strconv.Itoa(i))
+	}
+}
+
+// validateString 校验长度、正则和格式
+func (v *validator) validateString(s *Schema, str string, pointer string) {
+	n := utf8.RuneCountInString(str)
+	if s.MinLength != nil && n < *s.MinLength {
+		v.fail(pointer, "minLength", "长度 %d 小于 %d", n, *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		v.fail(pointer, "maxLength", "长度 %d 大于 %d", n, *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re, err := compile(s.Pattern)
+		if err != nil {
+			v.fail(pointer, "pattern", "无效的正则 %q: %v", s.Pattern, err)
+		} else if !re.MatchString(str) {
+			v.fail(pointer, "pattern", "%q 不匹配 %s", str, s.Pattern)
+		}
+	}
+	if check, ok := formats[s.Format]; ok && !check(str) {
+		v.fail(pointer, "format", "%q 不是合法的 %s", str, s.Format)
+	}
+}
+
+// validateNumber 校验取值范围
+func (v *validator) validateNumber(s *Schema, f float64, pointer string) {
+	if s.Minimum != nil && f < *s.Minimum {
+		v.fail(pointer, "minimum", "%v 小于最小值 %v", f, *s.Minimum)
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		v.fail(pointer, "maximum", "%v 大于最大值 %v", f, *s.Maximum)
+	}
+}
+
+// formats 支持校验的 format，其余 format 不检查
+var formats = map[string]func(string) bool{
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	},
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	},
+}
+
+// patterns 已编译的正则，Schema 通常会被反复使用
+var patterns sync.Map
+
+// compile 编译并缓存正则
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// hasType 判断 value 是否为 JSON Schema 类型 typ，integer 包括小数部分为0的数字
+func hasType(value interface{}, typ string) bool {
+	switch typ {
+	case "integer":
+		if !isNumber(value) {
+			return false
+		}
+		f := toFloat(value)
+		return f == math.Trunc(f) && !math.IsInf(f, 0)
+	case "number":
+		return isNumber(value)
+	}
+	return typeOf(value) == typ
+}
+
+// typeOf 返回 JSON 值的类型名，数字统一为 number
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if isNumber(value) {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// isNumber 判断是否为解码后的数字
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case json.Number, float64, int:
+		return true
+	}
+	return false
+}
+
+// toFloat 把解码后的数字转换为 float64
+func toFloat(value interface{}) float64 {
+	switch n := value.(type) {
+	case json.Number:
+		f, _ := n.Float64()
+		return f
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	}
+	return math.NaN()
+}
+
+// inEnum 判断 value 是否为枚举值之一，数字按数值比较
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if isNumber(value) && isNumber(e) {
+			if toFloat(value) == toFloat(e) {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(value, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// display 返回用于违规描述的值
+func display(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// escape 按 RFC 6901 转义 JSON Pointer 中的属性名
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
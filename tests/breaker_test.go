@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/vcr"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestCircuitBreaker 测试熔断器
+func TestCircuitBreaker(t *testing.T) {
+	runTest(t, "Circuit breaker", func(t provider.T) {
+		t.Tags("client", "circuit-breaker")
+		t.Description("验证连续5xx后熔断器打开并直接返回 ErrTargetUnavailable，冷却后半开探测，探测成功后关闭")
+		t.Severity(allure.NORMAL)
+
+		var healthy atomic.Bool
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			if !healthy.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		}))
+		t.Cleanup(server.Close)
+
+		u, err := url.Parse(server.URL)
+		t.Require().NoError(err)
+		host := u.Host
+
+		const cooldown = 100 * time.Millisecond
+		breaker := client.NewBreaker(3, cooldown)
+		apiClient := client.New(nil,
+			client.WithBaseURL(server.URL),
+			client.WithBreaker(breaker),
+			client.WithRetry(0, 0, 0),
+		)
+
+		t.WithNewStep("连续3次503后打开熔断器", func(sCtx provider.StepCtx) {
+			for i := 0; i < 3; i++ {
+				_, _, err := apiClient.GetAllProducts()
+				t.Require().Error(err, "503应该返回错误")
+			}
+			t.Assert().Equal(client.CircuitOpen, breaker.State(host))
+
+			start := time.Now()
+			_, _, err := apiClient.GetAllProducts()
+			sCtx.Logf("熔断时的错误: %v", err)
+			t.Require().True(errors.Is(err, client.ErrTargetUnavailable), "熔断时应该返回 ErrTargetUnavailable")
+			t.Assert().Less(time.Since(start), cooldown, "熔断时应该立即失败")
+			t.Assert().Equal(int32(3), calls.Load(), "熔断时请求不应该发送到服务")
+		})
+
+		t.WithNewStep("冷却后探测失败重新打开", func(sCtx provider.StepCtx) {
+			time.Sleep(cooldown)
+			t.Assert().Equal(client.CircuitHalfOpen, breaker.State(host))
+
+			_, _, err := apiClient.GetAllProducts()
+			t.Require().Error(err)
+			t.Assert().False(errors.Is(err, client.ErrTargetUnavailable), "半开时应该放行探测请求")
+			t.Assert().Equal(client.CircuitOpen, breaker.State(host), "探测失败后应该重新打开")
+		})
+
+		t.WithNewStep("冷却后探测成功关闭", func(sCtx provider.StepCtx) {
+			healthy.Store(true)
+			time.Sleep(cooldown)
+
+			_, _, err := apiClient.GetAllProducts()
+			t.Require().NoError(err, "服务恢复后探测请求应该成功")
+			t.Assert().Equal(client.CircuitClosed, breaker.State(host))
+
+			_, _, err = apiClient.GetAllProducts()
+			t.Assert().NoError(err, "熔断器关闭后应该正常放行")
+		})
+	})
+}
+
+// TestCircuitBreakerIgnoresSyntheticResults 测试熔断器不统计不是目标服务返回的结果
+func TestCircuitBreakerIgnoresSyntheticResults(t *testing.T) {
+	runTest(t, "Circuit breaker ignores synthetic results", func(t provider.T) {
+		t.Tags("client", "circuit-breaker")
+		t.Description("验证注入的故障和磁带回放失败不计入熔断器，不会熔断同一主机上的其他请求")
+		t.Severity(allure.NORMAL)
+
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		}))
+		t.Cleanup(server.Close)
+
+		u, err := url.Parse(server.URL)
+		t.Require().NoError(err)
+		host := u.Host
+
+		breaker := client.NewBreaker(1, time.Hour)
+
+		t.WithNewStep("注入的503和连接重置不打开熔断器", func(sCtx provider.StepCtx) {
+			faults := client.NewFaultInjector(
+				client.FaultRule{Fault: client.FaultStatus, Status: http.StatusServiceUnavailable, Times: 1},
+				client.FaultRule{Fault: client.FaultReset, Times: 1},
+			)
+			apiClient := client.New(nil,
+				client.WithBaseURL(server.URL),
+				client.WithBreaker(breaker),
+				client.WithFaults(faults),
+				client.WithRetry(0, 0, 0),
+			)
+			for i := 0; i < 2; i++ {
+				_, _, err := apiClient.GetAllProducts()
+				t.Require().Error(err, "注入的故障应该返回错误")
+				t.Assert().False(errors.Is(err, client.ErrTargetUnavailable), "注入的故障不应该触发熔断")
+			}
+			t.Assert().Equal(2, faults.Injected())
+			t.Assert().Equal(client.CircuitClosed, breaker.State(host))
+		})
+
+		t.WithNewStep("磁带中没有匹配的请求不打开熔断器", func(sCtx provider.StepCtx) {
+			matcher, err := vcr.NewMatcher(vcr.Fields)
+			t.Require().NoError(err)
+			apiClient := client.New(nil,
+				client.WithBaseURL(server.URL),
+				client.WithBreaker(breaker),
+				client.WithFaults(nil),
+				client.WithTransport(vcr.NewTransport(vcr.ModeReplay, &vcr.Cassette{}, matcher, nil)),
+				client.WithRetry(0, 0, 0),
+			)
+			_, _, err = apiClient.GetAllProducts()
+			t.Require().True(errors.Is(err, vcr.ErrNoInteraction), "空磁带应该返回 ErrNoInteraction")
+			t.Assert().Equal(client.CircuitClosed, breaker.State(host))
+		})
+
+		t.WithNewStep("真实请求照常放行", func(sCtx provider.StepCtx) {
+			apiClient := client.New(nil,
+				client.WithBaseURL(server.URL),
+				client.WithBreaker(breaker),
+				client.WithFaults(nil),
+				client.WithRetry(0, 0, 0),
+			)
+			_, _, err := apiClient.GetAllProducts()
+			t.Require().NoError(err)
+			t.Assert().Equal(int32(1), calls.Load(), "只有这一次请求发送到服务")
+		})
+	})
+}
+
+// failedT 报告用例已失败并记录 Broken 调用的 provider.T，其余方法交给真实的 t
+type failedT struct {
+	provider.T
+	broken bool
+}
+
+// Failed 实现 provider.T
+func (t *failedT) Failed() bool { return true }
+
+// Broken 实现 provider.T
+func (t *failedT) Broken() { t.broken = true }
+
+// TestCircuitBreakerMarksBroken 测试请求被熔断拒绝的用例标记为 broken
+func TestCircuitBreakerMarksBroken(t *testing.T) {
+	runTest(t, "Circuit breaker marks test broken", func(t provider.T) {
+		t.Tags("client", "circuit-breaker")
+		t.Description("验证 newTestClient 发出的请求被熔断拒绝后，失败的用例在 runTest 结束时标记为 broken")
+		t.Severity(allure.NORMAL)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(server.Close)
+
+		value, ok := states.Load(t.RealT().Name())
+		t.Require().True(ok, "runTest 应该保存用例状态")
+		state := value.(*testState)
+
+		t.WithNewStep("失败但没有被熔断的用例不标记", func(sCtx provider.StepCtx) {
+			probe := &failedT{T: t}
+			markUnavailable(probe, state)
+			t.Assert().False(probe.broken)
+		})
+
+		t.WithNewStep("熔断拒绝的请求记录在用例状态中", func(sCtx provider.StepCtx) {
+			// 使用真实的传输层和独立的熔断器，不受磁带、契约校验和共享熔断器的影响
+			apiClient := newTestClient(t,
+				client.WithBaseURL(server.URL),
+				client.WithTransport(http.DefaultTransport),
+				client.WithBreaker(client.NewBreaker(1, time.Hour)),
+				client.WithFaults(nil),
+				client.WithRetry(0, 0, 0),
+			)
+			_, _, err := apiClient.GetAllProducts()
+			t.Require().Error(err, "503应该返回错误")
+			t.Assert().False(state.unavailable.Load(), "5xx响应不是熔断拒绝")
+
+			_, _, err = apiClient.GetAllProducts()
+			t.Require().True(errors.Is(err, client.ErrTargetUnavailable), "熔断时应该返回 ErrTargetUnavailable")
+			t.Assert().True(state.unavailable.Load(), "熔断拒绝应该记录在用例状态中")
+		})
+
+		t.WithNewStep("失败且被熔断的用例标记为 broken", func(sCtx provider.StepCtx) {
+			probe := &failedT{T: t}
+			markUnavailable(probe, state)
+			t.Assert().True(probe.broken)
+		})
+	})
+}
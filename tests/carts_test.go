@@ -1,26 +1,30 @@
 package tests
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/config"
 	"go-testify-allure-api-test/models"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
-	"github.com/ozontech/allure-go/pkg/framework/runner"
 )
 
 // TestGetAllCarts 测试获取所有购物车
 func TestGetAllCarts(t *testing.T) {
-	runner.Run(t, "Test getting all carts from API", func(t provider.T) {
+	runTest(t, "Test getting all carts from API", func(t provider.T) {
 		t.Tags("api", "carts", "smoke")
 		t.Description("验证获取所有购物车的API功能")
 		t.Severity(allure.CRITICAL)
 
-		apiClient := client.NewAPIClient()
+		apiClient := newTestClient(t)
+		cfg := config.GetConfig()
 		var carts []models.Cart
 		var resp *resty.Response
 		var err error
@@ -35,7 +39,7 @@ func TestGetAllCarts(t *testing.T) {
 		})
 
 		t.WithNewStep("验证响应时间", func(sCtx provider.StepCtx) {
-			t.Require().True(resp.Time() < 5*time.Second, "响应时间应该在5秒内")
+			t.Require().True(resp.Time() < cfg.SLA.ListResponseTime, fmt.Sprintf("响应时间应该在%v内", cfg.SLA.ListResponseTime))
 		})
 
 		t.WithNewStep("验证购物车数据", func(sCtx provider.StepCtx) {
@@ -61,12 +65,13 @@ func TestGetAllCarts(t *testing.T) {
 
 // TestGetCartByID 测试根据ID获取购物车
 func TestGetCartByID(t *testing.T) {
-	runner.Run(t, "Test getting cart by ID from API", func(t provider.T) {
+	runTest(t, "Test getting cart by ID from API", func(t provider.T) {
 		t.Tags("api", "carts")
 		t.Description("验证根据ID获取购物车的API功能")
 		t.Severity(allure.NORMAL)
 
-		apiClient := client.NewAPIClient()
+		apiClient := newTestClient(t)
+		cfg := config.GetConfig()
 		cartID := 1
 		var cart *models.Cart
 		var resp *resty.Response
@@ -82,7 +87,7 @@ func TestGetCartByID(t *testing.T) {
 		})
 
 		t.WithNewStep("验证响应时间", func(sCtx provider.StepCtx) {
-			t.Require().True(resp.Time() < 3*time.Second, "响应时间应该在3秒内")
+			t.Require().True(resp.Time() < cfg.SLA.ItemResponseTime, fmt.Sprintf("响应时间应该在%v内", cfg.SLA.ItemResponseTime))
 		})
 
 		t.WithNewStep("验证购物车数据", func(sCtx provider.StepCtx) {
@@ -102,38 +107,48 @@ func TestGetCartByID(t *testing.T) {
 
 // TestGetCartByInvalidID 测试获取不存在的购物车
 func TestGetCartByInvalidID(t *testing.T) {
-	runner.Run(t, "Test getting cart by invalid ID", func(t provider.T) {
+	runTest(t, "Test getting cart by invalid ID", func(t provider.T) {
 		t.Tags("api", "carts", "negative")
 		t.Description("验证获取不存在购物车的API行为")
 		t.Severity(allure.NORMAL)
 
-		apiClient := client.NewAPIClient()
+		apiClient := newTestClient(t)
 		invalidID := 99999
+		var cart *models.Cart
 		var resp *resty.Response
 		var err error
 
 		t.WithNewStep("发送获取无效购物车的请求", func(sCtx provider.StepCtx) {
-			_, resp, err = apiClient.GetCartByID(invalidID)
+			cart, resp, err = apiClient.GetCartByID(invalidID)
 		})
 
 		t.WithNewStep("验证错误处理", func(sCtx provider.StepCtx) {
-			if err != nil || resp.StatusCode() == 404 {
-				t.Assert().True(resp.StatusCode() == 404 || err != nil, "请求不存在的购物车应该返回404或错误")
-			} else {
-				t.Assert().True(resp.StatusCode() == 200, "如果不返回404，应该返回200")
+			if !offline {
+				// 真实服务对不存在的ID返回200和null
+				t.Require().NoError(err, "真实服务请求不存在的购物车不应该返回错误")
+				t.Assert().Equal(200, resp.StatusCode(), "真实服务请求不存在的购物车应该返回200")
+				t.Assert().Equal("null", strings.TrimSpace(resp.String()), "真实服务请求不存在的购物车应该返回null")
+				t.Assert().Equal(models.Cart{}, *cart, "解码结果应该是零值")
+				return
 			}
+			var apiErr *client.APIError
+			t.Require().True(errors.As(err, &apiErr), "请求不存在的购物车应该返回 APIError")
+			t.Assert().Equal(404, apiErr.StatusCode, "请求不存在的购物车应该返回404")
+			t.Assert().Equal(404, resp.StatusCode(), "响应状态码应该与错误中的状态码一致")
+			t.Assert().Equal("GET", apiErr.Method, "错误中应该记录请求方法")
+			t.Assert().NotEmpty(apiErr.RequestID, "错误中应该记录请求ID")
 		})
 	})
 }
 
 // TestCartsDataConsistency 测试购物车数据一致性
 func TestCartsDataConsistency(t *testing.T) {
-	runner.Run(t, "Test carts data consistency", func(t provider.T) {
+	runTest(t, "Test carts data consistency", func(t provider.T) {
 		t.Tags("api", "carts", "consistency")
 		t.Description("验证购物车数据的一致性")
 		t.Severity(allure.CRITICAL)
 
-		apiClient := client.NewAPIClient()
+		apiClient := newTestClient(t)
 		var allCarts []models.Cart
 		var allProducts []models.Product
 		var resp *resty.Response
@@ -179,12 +194,12 @@ func TestCartsDataConsistency(t *testing.T) {
 
 // TestCartsPerformance 测试购物车API性能
 func TestCartsPerformance(t *testing.T) {
-	runner.Run(t, "Test carts API performance", func(t provider.T) {
+	runTest(t, "Test carts API performance", func(t provider.T) {
 		t.Tags("api", "carts", "performance")
 		t.Description("验证购物车API的性能表现")
 		t.Severity(allure.NORMAL)
 
-		apiClient := client.NewAPIClient()
+		apiClient := newTestClient(t)
 		var carts []models.Cart
 		var resp *resty.Response
 		var err error
@@ -209,4 +224,198 @@ func TestCartsPerformance(t *testing.T) {
 			}
 		})
 	})
-}
\ No newline at end of file
+}
+
+// TestGetCartsByUserID 测试根据用户ID获取购物车
+func TestGetCartsByUserID(t *testing.T) {
+	runTest(t, "Test getting carts by user ID", func(t provider.T) {
+		t.Tags("api", "carts", "user")
+		t.Description("验证根据用户ID获取购物车的API功能")
+		t.Severity(allure.NORMAL)
+
+		apiClient := newTestClient(t)
+		userID := 1
+		var carts []models.Cart
+		var resp *resty.Response
+		var err error
+
+		t.WithNewStep("发送获取用户购物车的请求", func(sCtx provider.StepCtx) {
+			carts, resp, err = apiClient.GetCartsByUserID(userID)
+			t.Require().NoError(err, "请求不应该返回错误")
+		})
+
+		t.WithNewStep("验证响应状态码", func(sCtx provider.StepCtx) {
+			t.Require().Equal(200, resp.StatusCode(), "获取用户购物车应该返回200状态码")
+		})
+
+		t.WithNewStep("验证购物车归属", func(sCtx provider.StepCtx) {
+			t.Require().NotEmpty(carts, "用户购物车列表不应该为空")
+			for _, cart := range carts {
+				t.Assert().Equal(userID, cart.UserID, fmt.Sprintf("购物车%d应该属于用户%d", cart.ID, userID))
+			}
+		})
+	})
+}
+
+// TestGetCartsByDateRange 测试根据日期范围获取购物车
+func TestGetCartsByDateRange(t *testing.T) {
+	runTest(t, "Test getting carts by date range", func(t provider.T) {
+		t.Tags("api", "carts", "date")
+		t.Description("验证根据日期范围获取购物车的API功能")
+		t.Severity(allure.NORMAL)
+
+		apiClient := newTestClient(t)
+		startDate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		endDate := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+		var carts []models.Cart
+		var resp *resty.Response
+		var err error
+
+		t.WithNewStep("发送按日期范围获取购物车的请求", func(sCtx provider.StepCtx) {
+			carts, resp, err = apiClient.GetCartsByDateRange(startDate, endDate)
+			t.Require().NoError(err, "请求不应该返回错误")
+		})
+
+		t.WithNewStep("验证响应状态码", func(sCtx provider.StepCtx) {
+			t.Require().Equal(200, resp.StatusCode(), "按日期范围获取购物车应该返回200状态码")
+		})
+
+		t.WithNewStep("验证购物车日期", func(sCtx provider.StepCtx) {
+			t.Require().NotEmpty(carts, "日期范围内应该有购物车")
+			for _, cart := range carts {
+				t.Assert().False(cart.Date.Before(startDate), fmt.Sprintf("购物车%d的日期不应该早于开始日期", cart.ID))
+				t.Assert().True(cart.Date.Before(endDate.AddDate(0, 0, 1)), fmt.Sprintf("购物车%d的日期不应该晚于结束日期", cart.ID))
+			}
+		})
+	})
+}
+
+// TestGetCartsByLimit 测试限制数量获取购物车
+func TestGetCartsByLimit(t *testing.T) {
+	runTest(t, "Test getting carts by limit", func(t provider.T) {
+		t.Tags("api", "carts", "limit")
+		t.Description("验证限制数量获取购物车的API功能")
+		t.Severity(allure.NORMAL)
+
+		apiClient := newTestClient(t)
+		limit := 3
+		var carts []models.Cart
+		var resp *resty.Response
+		var err error
+
+		t.WithNewStep("发送限制数量获取购物车的请求", func(sCtx provider.StepCtx) {
+			carts, resp, err = apiClient.GetCartsByLimit(limit)
+			t.Require().NoError(err, "请求不应该返回错误")
+		})
+
+		t.WithNewStep("验证购物车数量", func(sCtx provider.StepCtx) {
+			t.Require().Equal(200, resp.StatusCode(), "限制数量获取购物车应该返回200状态码")
+			t.Assert().LessOrEqual(len(carts), limit, "返回的购物车数量不应该超过限制")
+			t.Assert().Greater(len(carts), 0, "应该返回至少一个购物车")
+		})
+	})
+}
+
+// TestGetCartsBySort 测试排序获取购物车
+func TestGetCartsBySort(t *testing.T) {
+	runTest(t, "Test getting carts by sort", func(t provider.T) {
+		t.Tags("api", "carts", "sort")
+		t.Description("验证排序获取购物车的API功能")
+		t.Severity(allure.NORMAL)
+
+		apiClient := newTestClient(t)
+		var carts []models.Cart
+		var resp *resty.Response
+		var err error
+
+		t.WithNewStep("发送降序获取购物车的请求", func(sCtx provider.StepCtx) {
+			carts, resp, err = apiClient.GetCartsBySort("desc")
+			t.Require().NoError(err, "请求不应该返回错误")
+		})
+
+		t.WithNewStep("验证排序结果", func(sCtx provider.StepCtx) {
+			t.Require().Equal(200, resp.StatusCode(), "排序获取购物车应该返回200状态码")
+			t.Require().NotEmpty(carts, "购物车列表不应该为空")
+			for i := 1; i < len(carts); i++ {
+				t.Assert().Greater(carts[i-1].ID, carts[i].ID, "购物车应该按ID降序排列")
+			}
+		})
+	})
+}
+
+// TestCartLifecycle 测试购物车的完整生命周期
+func TestCartLifecycle(t *testing.T) {
+	runTest(t, "Test cart lifecycle", func(t provider.T) {
+		t.Tags("api", "carts", "crud")
+		t.Description("验证购物车的创建、更新、部分更新和删除")
+		t.Severity(allure.CRITICAL)
+
+		apiClient := newTestClient(t)
+		cartDate := time.Date(2020, 2, 3, 0, 0, 0, 0, time.UTC)
+		newCart := models.CreateCartRequest{
+			UserID: 5,
+			Date:   cartDate,
+			Products: []models.CartProduct{
+				{ProductID: 5, Quantity: 1},
+				{ProductID: 1, Quantity: 5},
+			},
+		}
+		var cart *models.Cart
+		var resp *resty.Response
+		var err error
+
+		t.WithNewStep("创建购物车", func(sCtx provider.StepCtx) {
+			cart, resp, err = apiClient.CreateCart(newCart)
+			t.Require().NoError(err, "创建购物车不应该返回错误")
+			t.Require().Equal(200, resp.StatusCode(), "创建购物车应该返回200状态码")
+			t.Require().Greater(cart.ID, 0, "创建的购物车应该有有效的ID")
+			t.Assert().Equal(newCart.UserID, cart.UserID, "用户ID应该匹配")
+			t.Assert().True(cartDate.Equal(cart.Date), "购物车日期应该匹配")
+			t.Assert().Equal(newCart.Products, cart.Products, "购物车商品应该匹配")
+		})
+
+		cartID := cart.ID
+		if !offline {
+			// 真实服务不保存新建的购物车，更新和删除改为针对已有的购物车，响应回显请求
+			cartID = 1
+		}
+
+		t.WithNewStep("更新购物车", func(sCtx provider.StepCtx) {
+			update := models.UpdateCartRequest{
+				UserID:   5,
+				Date:     &cartDate,
+				Products: []models.CartProduct{{ProductID: 3, Quantity: 2}},
+			}
+			cart, resp, err = apiClient.UpdateCart(cartID, update)
+			t.Require().NoError(err, "更新购物车不应该返回错误")
+			t.Require().Equal(200, resp.StatusCode(), "更新购物车应该返回200状态码")
+			t.Assert().Equal(cartID, cart.ID, "购物车ID应该保持不变")
+			t.Assert().Equal(update.Products, cart.Products, "购物车商品应该已更新")
+		})
+
+		t.WithNewStep("部分更新购物车", func(sCtx provider.StepCtx) {
+			patch := models.UpdateCartRequest{
+				Products: []models.CartProduct{{ProductID: 3, Quantity: 4}},
+			}
+			cart, resp, err = apiClient.PatchCart(cartID, patch)
+			t.Require().NoError(err, "部分更新购物车不应该返回错误")
+			t.Require().Equal(200, resp.StatusCode(), "部分更新购物车应该返回200状态码")
+			t.Assert().Equal(cartID, cart.ID, "购物车ID应该保持不变")
+			t.Assert().Equal(patch.Products, cart.Products, "购物车商品应该已更新")
+		})
+
+		t.WithNewStep("删除购物车", func(sCtx provider.StepCtx) {
+			cart, resp, err = apiClient.DeleteCart(cartID)
+			t.Require().NoError(err, "删除购物车不应该返回错误")
+			t.Require().Equal(200, resp.StatusCode(), "删除购物车应该返回200状态码")
+			t.Assert().Equal(cartID, cart.ID, "返回的购物车ID应该匹配删除的ID")
+		})
+
+		fakeStoreStep(t, "验证购物车已删除", func(sCtx provider.StepCtx) {
+			_, _, err = apiClient.GetCartByID(cartID)
+			var apiErr *client.APIError
+			t.Require().True(errors.As(err, &apiErr), "获取已删除的购物车应该返回 APIError")
+			t.Assert().Equal(404, apiErr.StatusCode, "获取已删除的购物车应该返回404")
+		})
+	})
+}
@@ -5,23 +5,23 @@ import (
 	"testing"
 	"time"
 
-	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/config"
 	"go-testify-allure-api-test/models"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
-	"github.com/ozontech/allure-go/pkg/framework/runner"
 )
 
 // TestGetAllCategories 测试获取所有分类
 func TestGetAllCategories(t *testing.T) {
-	runner.Run(t, "Test getting all categories from API", func(t provider.T) {
+	runTest(t, "Test getting all categories from API", func(t provider.T) {
 		t.Tags("api", "categories", "smoke")
 		t.Description("验证获取所有商品分类的API功能")
 		t.Severity(allure.CRITICAL)
 
-		apiClient := client.NewAPIClient()
+		apiClient := newTestClient(t)
+		cfg := config.GetConfig()
 		var categories []string
 		var resp *resty.Response
 		var err error
@@ -36,7 +36,7 @@ func TestGetAllCategories(t *testing.T) {
 		})
 
 		t.WithNewStep("验证响应时间", func(sCtx provider.StepCtx) {
-			t.Require().True(resp.Time() < 3*time.Second, "响应时间应该在3秒内")
+			t.Require().True(resp.Time() < cfg.SLA.ItemResponseTime, fmt.Sprintf("响应时间应该在%v内", cfg.SLA.ItemResponseTime))
 		})
 
 		t.WithNewStep("验证分类数据", func(sCtx provider.StepCtx) {
@@ -52,12 +52,13 @@ func TestGetAllCategories(t *testing.T) {
 
 // TestGetProductsByCategory 测试根据分类获取商品
 func TestGetProductsByCategory(t *testing.T) {
-	runner.Run(t, "Test getting products by category from API", func(t provider.T) {
+	runTest(t, "Test getting products by category from API", func(t provider.T) {
 		t.Tags("api", "categories", "products")
 		t.Description("验证根据分类获取商品的API功能")
 		t.Severity(allure.NORMAL)
 
-		apiClient := client.NewAPIClient()
+		apiClient := newTestClient(t)
+		cfg := config.GetConfig()
 		category := "electronics"
 		var products []models.Product
 		var resp *resty.Response
@@ -73,7 +74,7 @@ func TestGetProductsByCategory(t *testing.T) {
 		})
 
 		t.WithNewStep("验证响应时间", func(sCtx provider.StepCtx) {
-			t.Require().True(resp.Time() < 5*time.Second, "响应时间应该在5秒内")
+			t.Require().True(resp.Time() < cfg.SLA.ListResponseTime, fmt.Sprintf("响应时间应该在%v内", cfg.SLA.ListResponseTime))
 		})
 
 		t.WithNewStep("验证商品数据", func(sCtx provider.StepCtx) {
@@ -85,12 +86,12 @@ func TestGetProductsByCategory(t *testing.T) {
 
 // TestGetProductsByInvalidCategory 测试获取无效分类的商品
 func TestGetProductsByInvalidCategory(t *testing.T) {
-	runner.Run(t, "Test getting products by invalid category", func(t provider.T) {
+	runTest(t, "Test getting products by invalid category", func(t provider.T) {
 		t.Tags("api", "categories", "negative")
 		t.Description("验证获取不存在分类商品的API行为")
 		t.Severity(allure.NORMAL)
 
-		apiClient := client.NewAPIClient()
+		apiClient := newTestClient(t)
 		invalidCategory := "nonexistent"
 		var products []models.Product
 		var resp *resty.Response
@@ -112,12 +113,12 @@ func TestGetProductsByInvalidCategory(t *testing.T) {
 
 // TestCategoryDataConsistency 测试分类数据一致性
 func TestCategoryDataConsistency(t *testing.T) {
-	runner.Run(t, "Test category data consistency", func(t provider.T) {
+	runTest(t, "Test category data consistency", func(t provider.T) {
 		t.Tags("api", "categories", "consistency")
 		t.Description("验证分类数据的一致性")
 		t.Severity(allure.CRITICAL)
 
-		apiClient := client.NewAPIClient()
+		apiClient := newTestClient(t)
 		var categories []string
 		var allProducts []models.Product
 		var resp *resty.Response
@@ -155,12 +156,12 @@ func TestCategoryDataConsistency(t *testing.T) {
 
 // TestCategoryPerformance 测试分类API性能
 func TestCategoryPerformance(t *testing.T) {
-	runner.Run(t, "Test category API performance", func(t provider.T) {
+	runTest(t, "Test category API performance", func(t provider.T) {
 		t.Tags("api", "categories", "performance")
 		t.Description("验证分类API的性能表现")
 		t.Severity(allure.NORMAL)
 
-		apiClient := client.NewAPIClient()
+		apiClient := newTestClient(t)
 		var categories []string
 		var resp *resty.Response
 		var err error
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/fakestore"
+	"go-testify-allure-api-test/models"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestCleanupRegistry 测试资源清理登记表
+func TestCleanupRegistry(t *testing.T) {
+	runTest(t, "Cleanup registry", func(t provider.T) {
+		t.Tags("client", "cleanup")
+		t.Description("验证创建的资源被删除、修改的资源被恢复，并按相反顺序执行")
+		t.Severity(allure.NORMAL)
+
+		server := fakestore.NewServer()
+		t.Cleanup(server.Close)
+
+		registry := client.NewCleanup()
+		apiClient := client.New(nil, client.WithBaseURL(server.URL()), client.WithCleanup(registry))
+
+		original, ok := server.Store().Product(1)
+		t.Require().True(ok, "替身服务应该包含商品1")
+
+		var created *models.Product
+		var results []client.CleanupResult
+
+		t.WithNewStep("创建、修改和删除资源", func(sCtx provider.StepCtx) {
+			var err error
+			created, _, err = apiClient.CreateProduct(models.CreateProductRequest{
+				Title: "cleanup", Price: 1, Description: "cleanup", Image: "https://example.com/a.jpg", Category: "test",
+			})
+			t.Require().NoError(err, "创建商品不应该返回错误")
+
+			_, _, err = apiClient.UpdateProduct(1, models.UpdateProductRequest{Title: "changed", Price: 2})
+			t.Require().NoError(err, "更新商品不应该返回错误")
+			_, _, err = apiClient.PatchProduct(1, models.UpdateProductRequest{Title: "changed again"})
+			t.Require().NoError(err, "部分更新商品不应该返回错误")
+
+			cart, _, err := apiClient.CreateCart(models.CreateCartRequest{UserID: 1})
+			t.Require().NoError(err, "创建购物车不应该返回错误")
+			_, _, err = apiClient.DeleteCart(cart.ID)
+			t.Require().NoError(err, "删除购物车不应该返回错误")
+
+			_, _, err = apiClient.DeleteUser(3)
+			t.Require().NoError(err, "删除用户不应该返回错误")
+
+			t.Assert().Equal(3, registry.Len(), "已删除的新建购物车不需要清理")
+		})
+
+		t.WithNewStep("执行清理", func(sCtx provider.StepCtx) {
+			results = registry.Run(context.Background())
+			t.Require().Len(results, 3)
+			t.Assert().Equal(client.CleanupResult{Action: "RESTORE /users/3", Status: client.CleanupSkipped, Err: results[0].Err}, results[0])
+			t.Assert().Error(results[0].Err, "跳过的清理应该说明原因")
+			t.Assert().Equal(client.CleanupResult{Action: "PUT /products/1", Status: client.CleanupRestored}, results[1])
+			t.Assert().Equal(client.CleanupResult{Action: fmt.Sprintf("DELETE /products/%d", created.ID), Status: client.CleanupDeleted}, results[2])
+			t.Assert().Equal(0, registry.Len(), "清理后登记表应该为空")
+		})
+
+		t.WithNewStep("验证资源已撤销", func(sCtx provider.StepCtx) {
+			restored, _, err := apiClient.GetProductByID(1)
+			t.Require().NoError(err)
+			t.Assert().Equal(original.Title, restored.Title, "商品1应该恢复为修改前的标题")
+			t.Assert().Equal(original.Price, restored.Price, "商品1应该恢复为修改前的价格")
+
+			_, _, err = apiClient.GetProductByID(created.ID)
+			var apiErr *client.APIError
+			t.Require().True(errors.As(err, &apiErr), "新建的商品应该已被删除")
+			t.Assert().Equal(404, apiErr.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/config"
+	"go-testify-allure-api-test/fakestore"
+	"go-testify-allure-api-test/models"
+	"go-testify-allure-api-test/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestClientWithInjectedConfig 测试使用独立配置创建客户端
+func TestClientWithInjectedConfig(t *testing.T) {
+	runTest(t, "Client with injected config", func(t provider.T) {
+		t.Tags("client", "config")
+		t.Description("验证 config.Load 返回独立配置，client.New 使用该配置访问本地替身服务")
+		t.Severity(allure.NORMAL)
+
+		server := fakestore.NewServer()
+		t.Cleanup(server.Close)
+
+		var cfg *config.Config
+		var err error
+
+		t.WithNewStep("从临时配置文件加载独立配置", func(sCtx provider.StepCtx) {
+			dir, mkErr := os.MkdirTemp("", "apitest-config")
+			t.Require().NoError(mkErr, "创建临时目录不应该返回错误")
+			t.Cleanup(func() { os.RemoveAll(dir) })
+
+			path := filepath.Join(dir, "config.yaml")
+			content := "api:\n  base_url: \"" + server.URL() + "\"\n  timeout: 5\n  retry_count: 0\n"
+			t.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+
+			cfg, err = config.Load(path, config.WithoutEnv())
+			t.Require().NoError(err, "加载配置不应该返回错误")
+			t.Assert().Equal(server.URL(), cfg.API.BaseURL, "base_url 应该来自配置文件")
+			t.Assert().NotSame(config.GetConfig(), cfg, "Load 应该返回独立的配置实例")
+		})
+
+		t.WithNewStep("使用独立配置创建客户端并发送请求", func(sCtx provider.StepCtx) {
+			apiClient := client.New(cfg)
+			t.Assert().Equal(server.URL(), apiClient.BaseURL(), "客户端应该使用注入的基础地址")
+
+			products, resp, reqErr := apiClient.GetProductsByLimit(2)
+			t.Require().NoError(reqErr, "请求不应该返回错误")
+			t.Assert().Equal(200, resp.StatusCode(), "请求应该返回200状态码")
+			t.Assert().Len(products, 2, "应该返回2个商品")
+		})
+	})
+}
+
+// TestClientOptions 测试客户端函数式选项
+func TestClientOptions(t *testing.T) {
+	runTest(t, "Client functional options", func(t provider.T) {
+		t.Tags("client", "options")
+		t.Description("验证基础地址、请求头和超时选项覆盖配置中的值")
+		t.Severity(allure.NORMAL)
+
+		server := fakestore.NewServer()
+		t.Cleanup(server.Close)
+
+		t.WithNewStep("覆盖基础地址和请求头", func(sCtx provider.StepCtx) {
+			apiClient := client.New(nil,
+				client.WithBaseURL(server.URL()),
+				client.WithHeader("X-Test-Suite", "client-options"),
+			)
+			_, resp, err := apiClient.GetCartByID(1)
+			t.Require().NoError(err, "请求不应该返回错误")
+			t.Assert().Equal(server.URL(), apiClient.BaseURL(), "客户端应该使用覆盖后的基础地址")
+			t.Assert().Equal("client-options", resp.Request.Header.Get("X-Test-Suite"), "请求应该携带自定义请求头")
+		})
+
+		t.WithNewStep("覆盖超时时间", func(sCtx provider.StepCtx) {
+			slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				w.Write([]byte("[]"))
+			}))
+			t.Cleanup(slow.Close)
+
+			apiClient := client.New(nil,
+				client.WithBaseURL(slow.URL),
+				client.WithTimeout(50*time.Millisecond),
+				client.WithRetry(0, 0, 0),
+			)
+			_, _, err := apiClient.GetAllProducts()
+			t.Assert().Error(err, "超过超时时间的请求应该返回错误")
+		})
+	})
+}
+
+// TestClientConcurrentUse 测试在多个 goroutine 中共享同一个客户端
+func TestClientConcurrentUse(t *testing.T) {
+	runTest(t, "Client concurrent use", func(t provider.T) {
+		t.Tags("client", "concurrency")
+		t.Description("验证同一个客户端可以并发发送请求，并发设置认证令牌不会相互干扰（配合 -race 运行）")
+		t.Severity(allure.NORMAL)
+
+		server := fakestore.NewServer()
+		t.Cleanup(server.Close)
+
+		apiClient := client.New(nil, client.WithBaseURL(server.URL()), client.WithTestName(t.Name()))
+
+		const workers = 8
+		errs := make(chan error, workers)
+		tokens := make(chan string, workers)
+
+		t.WithNewStep("并发发送请求并设置认证令牌", func(sCtx provider.StepCtx) {
+			var wg sync.WaitGroup
+			for i := 1; i <= workers; i++ {
+				wg.Add(1)
+				go func(id int) {
+					defer wg.Done()
+					apiClient.SetAuthToken(fmt.Sprintf("token-%d", id))
+					_, resp, err := apiClient.GetProductByID(id)
+					if err != nil {
+						errs <- err
+						return
+					}
+					tokens <- resp.Request.Token
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			close(tokens)
+		})
+
+		t.WithNewStep("验证所有请求成功且都携带了令牌", func(sCtx provider.StepCtx) {
+			for err := range errs {
+				t.Assert().NoError(err, "并发请求不应该返回错误")
+			}
+			count := 0
+			for token := range tokens {
+				count++
+				t.Assert().True(strings.HasPrefix(token, "token-"), "请求应该携带设置的认证令牌")
+			}
+			t.Assert().Equal(workers, count, "所有请求都应该成功")
+		})
+	})
+}
+
+// TestClientAllureAttachments 测试请求和响应自动写入 Allure 附件
+func TestClientAllureAttachments(t *testing.T) {
+	runTest(t, "Client Allure attachments", func(t provider.T) {
+		t.Tags("client", "allure")
+		t.Description("验证每次请求的请求行、请求头、请求体、响应状态、响应头、响应体和耗时写入当前步骤的附件")
+		t.Severity(allure.NORMAL)
+
+		server := fakestore.NewServer()
+		t.Cleanup(server.Close)
+
+		reporter, ok := t.(*utils.AllureReporter)
+		t.Require().True(ok, "runTest 应该传入 AllureReporter")
+		apiClient := client.New(nil, client.WithBaseURL(server.URL()), client.WithHook(reporter.Hook))
+
+		t.WithNewStep("登录并检查当前步骤的附件", func(sCtx provider.StepCtx) {
+			apiClient.SetAuthToken("secret-token")
+			_, _, err := apiClient.Login(models.LoginRequest{Username: "mor_2314", Password: "83r5^_"})
+			t.Require().NoError(err, "登录不应该返回错误")
+
+			attachments := sCtx.CurrentStep().Attachments
+			t.Require().Len(attachments, 3, "一次请求应该生成请求、响应和 cURL 三个附件")
+			t.Assert().Equal("POST /auth/login 请求", attachments[0].Name)
+			t.Assert().Equal("POST /auth/login 响应", attachments[1].Name)
+			t.Assert().Equal("POST /auth/login cURL", attachments[2].Name)
+
+			request := string(attachments[0].GetContent())
+			t.Assert().Contains(request, "POST "+server.URL()+"/auth/login", "请求附件应该包含请求行")
+			t.Assert().Contains(request, "Authorization: ******", "敏感请求头应该被隐藏")
+			t.Assert().NotContains(request, "secret-token", "附件中不应该出现令牌")
+			t.Assert().Contains(request, "{\n  \"username\": \"mor_2314\"", "请求体应该格式化为缩进的 JSON")
+			t.Assert().Contains(request, "\"password\": \"******\"", "请求体中的密码应该被隐藏")
+			t.Assert().NotContains(request, "83r5^_", "附件中不应该出现密码")
+			curl := string(attachments[2].GetContent())
+			t.Assert().NotContains(curl, "83r5^_", "cURL 附件中不应该出现密码")
+			t.Assert().Contains(curl, `--data-raw '{"username":"mor_2314","password":"******"}'`, "cURL 附件中其余字段应该按原有顺序保留")
+
+			response := string(attachments[1].GetContent())
+			t.Assert().Contains(response, "HTTP/1.1 200 OK", "响应附件应该包含状态行")
+			t.Assert().Contains(response, "Duration: ", "响应附件应该包含耗时")
+			t.Assert().Contains(response, "Content-Type: application/json", "响应附件应该包含响应头")
+			t.Assert().Contains(response, "\"token\": ", "响应附件应该包含响应体")
+		})
+	})
+}
+
+// TestClientCurlCommand 测试为请求生成可复现的 curl 命令
+func TestClientCurlCommand(t *testing.T) {
+	runTest(t, "Client cURL command", func(t provider.T) {
+		t.Tags("client", "curl")
+		t.Description("验证 curl 命令经过 shell 转义、隐藏敏感请求头、原样保留请求体，并可从响应和 APIError 中获取")
+		t.Severity(allure.NORMAL)
+
+		server := fakestore.NewServer()
+		t.Cleanup(server.Close)
+
+		apiClient := client.New(nil, client.WithBaseURL(server.URL()))
+		apiClient.SetAuthToken("secret-token")
+
+		t.WithNewStep("从响应获取 curl 命令并由 shell 解析", func(sCtx provider.StepCtx) {
+			product := models.CreateProductRequest{Title: "it's a \"quoted\" $HOME `title`", Price: 1, Category: "test"}
+			_, resp, err := apiClient.CreateProduct(product)
+			t.Require().NoError(err, "创建商品不应该返回错误")
+
+			curl := client.Curl(resp)
+			sCtx.WithNewAttachment("curl.sh", allure.Text, []byte(curl))
+			t.Require().True(strings.HasPrefix(curl, "curl -X POST "), "命令应该以 curl -X POST 开头")
+			t.Assert().NotContains(curl, "secret-token", "命令中不应该出现令牌")
+
+			// 用 printf 代替 curl，检查 shell 解析出的参数与实际请求一致
+			out, err := exec.Command("sh", "-c", "printf '%s\\n' "+strings.TrimPrefix(curl, "curl ")).Output()
+			t.Require().NoError(err, "shell 应该能解析 curl 命令")
+			args := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+
+			body, _ := json.Marshal(product)
+			t.Assert().Equal([]string{"-X", "POST", server.URL() + "/products"}, args[:3], "方法和地址应该与请求一致")
+			t.Assert().Contains(args, "Authorization: ******", "Authorization 应该被隐藏")
+			t.Assert().Contains(args, "Content-Type: application/json", "应该包含请求头")
+			t.Assert().Equal([]string{"--data-raw", string(body)}, args[len(args)-2:], "请求体应该原样还原")
+		})
+
+		t.WithNewStep("请求体中的敏感字段原样保留，RedactBody 隐藏敏感字段", func(sCtx provider.StepCtx) {
+			_, resp, err := apiClient.Login(models.LoginRequest{Username: "mor_2314", Password: "83r5^_"})
+			t.Require().NoError(err, "登录不应该返回错误")
+
+			curl := client.Curl(resp)
+			t.Assert().True(strings.HasSuffix(curl, `--data-raw '{"username":"mor_2314","password":"83r5^_"}'`),
+				"命令应该能原样复现请求，请求体不隐藏")
+
+			t.Assert().Equal(`{"user":{"name":"a","Token":"******"},"items":[{"secret":"******"}],"n":1.50}`,
+				string(client.RedactBody([]byte(`{"user":{"name":"a","Token":"t"},"items":[{"secret":{"k":[1,2]}}],"n":1.50}`))),
+				"任意层级的敏感字段都应该被隐藏")
+			t.Assert().Equal(`{"title":"<b>"}`, string(client.RedactBody([]byte(`{"title":"<b>"}`))), "没有敏感字段时原样返回")
+			t.Assert().Equal("password=x", string(client.RedactBody([]byte("password=x"))), "不是 JSON 时原样返回")
+		})
+
+		t.WithNewStep("从 APIError 获取 curl 命令", func(sCtx provider.StepCtx) {
+			_, _, err := apiClient.GetProductByID(99999)
+			var apiErr *client.APIError
+			t.Require().True(errors.As(err, &apiErr), "请求不存在的商品应该返回 APIError")
+			t.Assert().True(strings.HasPrefix(apiErr.Curl, "curl -X GET '"+server.URL()+"/products/99999'"), "APIError 应该包含复现请求的 curl 命令")
+		})
+	})
+}
@@ -0,0 +1,280 @@
+package tests
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/config"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// fakeEnv 构造环境变量查找函数
+func fakeEnv(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+// TestConfigOverridePrecedence 测试配置覆盖优先级
+func TestConfigOverridePrecedence(t *testing.T) {
+	runTest(t, "Config override precedence", func(t provider.T) {
+		t.Tags("config")
+		t.Description("验证命令行参数 > 环境变量 > 配置文件 > 默认值的优先级")
+		t.Severity(allure.NORMAL)
+
+		var cfg *config.Config
+		var err error
+
+		t.WithNewStep("同时通过环境变量和命令行参数覆盖配置", func(sCtx provider.StepCtx) {
+			cfg, err = config.Resolve(config.Overrides{
+				Env: fakeEnv(map[string]string{
+					"APITEST_API_BASE_URL": "https://env.example.com",
+					"APITEST_API_TIMEOUT":  "45",
+					"APITEST_TEST_CLEANUP": "false",
+				}),
+				Flags: map[string]string{
+					"api.base_url": "https://flag.example.com",
+				},
+			})
+			t.Require().NoError(err, "解析配置不应该返回错误")
+			sCtx.WithNewAttachment("effective-config.txt", allure.Text, []byte(cfg.Report()))
+		})
+
+		t.WithNewStep("验证命令行参数优先于环境变量", func(sCtx provider.StepCtx) {
+			t.Assert().Equal("https://flag.example.com", cfg.API.BaseURL, "base_url 应该取命令行参数的值")
+			t.Assert().Equal(config.SourceFlag, cfg.Source("api.base_url"), "base_url 的来源应该是命令行参数")
+		})
+
+		t.WithNewStep("验证环境变量覆盖并完成类型转换", func(sCtx provider.StepCtx) {
+			t.Assert().Equal(45, cfg.API.Timeout, "timeout 应该取环境变量的值")
+			t.Assert().Equal(config.SourceEnv, cfg.Source("api.timeout"), "timeout 的来源应该是环境变量")
+			t.Assert().False(cfg.Test.Cleanup, "cleanup 应该取环境变量的值")
+			t.Assert().Equal(config.SourceEnv, cfg.Source("test.cleanup"), "cleanup 的来源应该是环境变量")
+		})
+
+		t.WithNewStep("验证未覆盖的配置项保持原来源", func(sCtx provider.StepCtx) {
+			source := cfg.Source("api.retry_count")
+			t.Assert().Contains([]config.Source{config.SourceDefault, config.SourceFile}, source, "retry_count 应该来自默认值或配置文件")
+		})
+	})
+}
+
+// TestConfigUnknownFlag 测试未知配置项
+func TestConfigUnknownFlag(t *testing.T) {
+	runTest(t, "Config rejects unknown override key", func(t provider.T) {
+		t.Tags("config", "negative")
+		t.Description("验证覆盖不存在的配置项时返回错误")
+		t.Severity(allure.MINOR)
+
+		t.WithNewStep("使用未知配置项解析配置", func(sCtx provider.StepCtx) {
+			_, err := config.Resolve(config.Overrides{
+				Env:   fakeEnv(nil),
+				Flags: map[string]string{"api.unknown": "value"},
+			})
+			t.Require().Error(err, "未知配置项应该返回错误")
+			t.Assert().Contains(err.Error(), "api.unknown", "错误信息应该包含配置项名称")
+		})
+	})
+}
+
+// TestConfigEnvName 测试环境变量命名
+func TestConfigEnvName(t *testing.T) {
+	runTest(t, "Config environment variable and flag names", func(t provider.T) {
+		t.Tags("config")
+		t.Description("验证配置项与环境变量名、命令行参数名的对应关系")
+		t.Severity(allure.MINOR)
+
+		t.WithNewStep("验证环境变量名和命令行参数名", func(sCtx provider.StepCtx) {
+			t.Assert().Equal("APITEST_API_BASE_URL", config.EnvName("api.base_url"))
+			t.Assert().Equal("APITEST_LOGGING_LEVEL", config.EnvName("logging.level"))
+			t.Assert().Equal("apitest.test.parallel", config.FlagName("test.parallel"))
+		})
+
+		t.WithNewStep("命令行参数注册在指定的 FlagSet 上", func(sCtx provider.StepCtx) {
+			fs := flag.NewFlagSet("apitest", flag.ContinueOnError)
+			config.RegisterFlags(fs)
+			t.Assert().NotNil(fs.Lookup("apitest.api.base_url"), "应该注册 api.base_url 对应的参数")
+			t.Require().NoError(fs.Parse([]string{"-apitest.vcr.mode=replay"}))
+			t.Assert().Equal("replay", fs.Lookup("apitest.vcr.mode").Value.String())
+		})
+	})
+}
+
+// writeProfilesConfig 写入包含环境配置的临时配置文件
+func writeProfilesConfig(t provider.T) string {
+	dir, err := os.MkdirTemp("", "apitest-config")
+	t.Require().NoError(err, "创建临时目录不应该返回错误")
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	content := `
+environment: "staging"
+api:
+  base_url: "https://fakestoreapi.com"
+  timeout: 30
+environments:
+  staging:
+    api:
+      base_url: "https://staging.example.com"
+      timeout: 15
+    auth:
+      username: "staging_user"
+      password: "staging_pass"
+    sla:
+      item_response_time: "800ms"
+  local:
+    api:
+      base_url: "http://localhost:3000"
+`
+	path := filepath.Join(dir, "config.yaml")
+	t.Require().NoError(os.WriteFile(path, []byte(content), 0644), "写入配置文件不应该返回错误")
+	return path
+}
+
+// TestConfigEnvironmentProfile 测试环境配置
+func TestConfigEnvironmentProfile(t *testing.T) {
+	runTest(t, "Config environment profiles", func(t provider.T) {
+		t.Tags("config", "environment")
+		t.Description("验证选中的环境配置覆盖基础配置，并且可被环境变量和命令行参数再次覆盖")
+		t.Severity(allure.NORMAL)
+
+		path := writeProfilesConfig(t)
+		var cfg *config.Config
+		var err error
+
+		t.WithNewStep("使用配置文件中选中的 staging 环境", func(sCtx provider.StepCtx) {
+			cfg, err = config.Resolve(config.Overrides{ConfigFile: path, Env: fakeEnv(nil)})
+			t.Require().NoError(err, "解析配置不应该返回错误")
+			sCtx.WithNewAttachment("effective-config.txt", allure.Text, []byte(cfg.Report()))
+
+			t.Assert().Equal("staging", cfg.ProfileName(), "应该选中 staging 环境")
+			t.Assert().Equal("https://staging.example.com", cfg.API.BaseURL, "base_url 应该来自环境配置")
+			t.Assert().Equal(config.SourceProfile, cfg.Source("api.base_url"), "base_url 的来源应该是环境配置")
+			t.Assert().Equal(15, cfg.API.Timeout, "timeout 应该来自环境配置")
+			t.Assert().Equal("staging_user", cfg.Auth.Username, "用户名应该来自环境配置")
+			t.Assert().Equal(800*time.Millisecond, cfg.SLA.ItemResponseTime, "SLA 应该来自环境配置")
+			t.Assert().Equal(config.SourceDefault, cfg.Source("sla.list_response_time"), "未覆盖的 SLA 应该保持默认值")
+			t.Assert().NotContains(cfg.Report(), "staging_pass", "配置报告不应该包含密码")
+		})
+
+		t.WithNewStep("通过环境变量切换到 local 环境", func(sCtx provider.StepCtx) {
+			cfg, err = config.Resolve(config.Overrides{
+				ConfigFile: path,
+				Env:        fakeEnv(map[string]string{"APITEST_ENVIRONMENT": "local"}),
+			})
+			t.Require().NoError(err, "解析配置不应该返回错误")
+			t.Assert().Equal("local", cfg.ProfileName(), "应该选中 local 环境")
+			t.Assert().Equal("http://localhost:3000", cfg.API.BaseURL, "base_url 应该来自 local 环境")
+			t.Assert().Equal(30, cfg.API.Timeout, "local 环境未覆盖的 timeout 应该来自基础配置")
+			t.Assert().Equal(config.SourceFile, cfg.Source("api.timeout"), "timeout 的来源应该是配置文件")
+		})
+
+		t.WithNewStep("命令行参数优先于环境配置", func(sCtx provider.StepCtx) {
+			cfg, err = config.Resolve(config.Overrides{
+				ConfigFile: path,
+				Env:        fakeEnv(nil),
+				Flags:      map[string]string{"api.base_url": "https://flag.example.com"},
+			})
+			t.Require().NoError(err, "解析配置不应该返回错误")
+			t.Assert().Equal("https://flag.example.com", cfg.API.BaseURL, "base_url 应该取命令行参数的值")
+		})
+
+		t.WithNewStep("选择不存在的环境", func(sCtx provider.StepCtx) {
+			_, err = config.Resolve(config.Overrides{
+				ConfigFile: path,
+				Flags:      map[string]string{"environment": "qa"},
+				Env:        fakeEnv(nil),
+			})
+			t.Require().Error(err, "不存在的环境应该返回错误")
+			t.Assert().Contains(err.Error(), "local, staging", "错误信息应该列出可用的环境")
+		})
+	})
+}
+
+// TestConfigValidation 测试配置校验
+func TestConfigValidation(t *testing.T) {
+	runTest(t, "Config validation", func(t provider.T) {
+		t.Tags("config", "validation")
+		t.Description("验证配置校验一次性返回所有问题")
+		t.Severity(allure.CRITICAL)
+
+		t.WithNewStep("默认配置应该通过校验", func(sCtx provider.StepCtx) {
+			cfg, err := config.Resolve(config.Overrides{Env: fakeEnv(nil)})
+			t.Require().NoError(err, "解析配置不应该返回错误")
+			t.Assert().NoError(cfg.Validate(), "默认配置应该有效")
+		})
+
+		t.WithNewStep("包含多个问题的配置", func(sCtx provider.StepCtx) {
+			blocker, err := os.CreateTemp("", "apitest-blocker")
+			t.Require().NoError(err, "创建临时文件不应该返回错误")
+			blocker.Close()
+			t.Cleanup(func() { os.Remove(blocker.Name()) })
+
+			cfg, err := config.Resolve(config.Overrides{
+				Env: fakeEnv(map[string]string{
+					"APITEST_API_BASE_URL":       "fakestoreapi.com",
+					"APITEST_API_TIMEOUT":        "-1",
+					"APITEST_API_RETRY_COUNT":    "500",
+					"APITEST_LOGGING_LEVEL":      "verbose",
+					"APITEST_LOGGING_FORMAT":     "xml",
+					"APITEST_ALLURE_RESULTS_DIR": filepath.Join(blocker.Name(), "results"),
+				}),
+			})
+			t.Require().NoError(err, "解析配置不应该返回错误")
+
+			err = cfg.Validate()
+			t.Require().Error(err, "无效配置应该校验失败")
+			sCtx.WithNewAttachment("validation-error.txt", allure.Text, []byte(err.Error()))
+
+			var validationErr *config.ValidationError
+			t.Require().True(errors.As(err, &validationErr), "错误应该是 ValidationError")
+
+			keys := make([]string, 0, len(validationErr.Problems))
+			for _, p := range validationErr.Problems {
+				keys = append(keys, p.Key)
+			}
+			t.Assert().ElementsMatch([]string{
+				"api.base_url",
+				"api.timeout",
+				"api.retry_count",
+				"logging.level",
+				"logging.format",
+				"allure.results_dir",
+			}, keys, "应该报告所有问题")
+		})
+
+		t.WithNewStep("retry.exclude_methods 不区分大小写", func(sCtx provider.StepCtx) {
+			cfg, err := config.Resolve(config.Overrides{
+				Env: fakeEnv(map[string]string{"APITEST_RETRY_EXCLUDE_METHODS": "post,Patch"}),
+			})
+			t.Require().NoError(err, "解析配置不应该返回错误")
+			t.Require().Equal([]string{"post", "Patch"}, cfg.Retry.ExcludeMethods)
+			t.Assert().NoError(cfg.Validate(), "小写的请求方法应该有效")
+			t.Assert().False(client.NewRetryPolicy(cfg).ShouldRetry("PATCH", 503, nil), "排除的方法不应该重试")
+
+			cfg.Retry.ExcludeMethods = []string{"fetch"}
+			err = cfg.Validate()
+			t.Require().Error(err, "未知的请求方法应该校验失败")
+			t.Assert().Contains(err.Error(), "retry.exclude_methods")
+		})
+
+		t.WithNewStep("损坏的配置文件应该直接报错", func(sCtx provider.StepCtx) {
+			dir, err := os.MkdirTemp("", "apitest-config")
+			t.Require().NoError(err, "创建临时目录不应该返回错误")
+			t.Cleanup(func() { os.RemoveAll(dir) })
+
+			path := filepath.Join(dir, "config.yaml")
+			t.Require().NoError(os.WriteFile(path, []byte("api: [unclosed"), 0644))
+
+			_, err = config.Resolve(config.Overrides{ConfigFile: path, Env: fakeEnv(nil)})
+			t.Assert().Error(err, "损坏的配置文件不应该回退到默认值")
+		})
+	})
+}
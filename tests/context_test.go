@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/config"
+	"go-testify-allure-api-test/models"
+	"go-testify-allure-api-test/utils"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestRequestWithStepDeadline 测试在步骤截止时间内完成请求
+func TestRequestWithStepDeadline(t *testing.T) {
+	runTest(t, "Request within step deadline", func(t provider.T) {
+		t.Tags("api", "context")
+		t.Description("验证带截止时间的请求在期限内正常返回")
+		t.Severity(allure.NORMAL)
+
+		apiClient := newTestClient(t)
+		var products []models.Product
+		var resp *resty.Response
+		var err error
+
+		t.WithNewStep("在5秒截止时间内获取商品列表", func(sCtx provider.StepCtx) {
+			ctx, cancel := utils.StepContext(sCtx, 5*time.Second)
+			defer cancel()
+			products, resp, err = apiClient.GetAllProductsWithContext(ctx)
+		})
+
+		t.WithNewStep("验证响应", func(sCtx provider.StepCtx) {
+			t.Require().NoError(err, "请求不应该返回错误")
+			t.Require().Equal(200, resp.StatusCode(), "获取商品列表应该返回200状态码")
+			t.Assert().NotEmpty(products, "商品列表不应该为空")
+		})
+	})
+}
+
+// TestRequestExceedingStepDeadline 测试超过截止时间的请求
+func TestRequestExceedingStepDeadline(t *testing.T) {
+	runTest(t, "Request exceeding step deadline", func(t provider.T) {
+		t.Tags("api", "context", "negative")
+		t.Description("验证超过截止时间的请求返回可区分的超时错误")
+		t.Severity(allure.NORMAL)
+
+		apiClient := newTestClient(t)
+		var err error
+
+		t.WithNewStep("在1纳秒截止时间内获取购物车", func(sCtx provider.StepCtx) {
+			ctx, cancel := utils.StepContext(sCtx, time.Nanosecond)
+			defer cancel()
+			_, _, err = apiClient.GetCartByIDWithContext(ctx, 1)
+		})
+
+		t.WithNewStep("验证超时错误", func(sCtx provider.StepCtx) {
+			sCtx.Logf("返回的错误: %v", err)
+			t.Require().Error(err, "超过截止时间的请求应该返回错误")
+			t.Assert().True(errors.Is(err, client.ErrRequestTimeout), "错误应该是 ErrRequestTimeout")
+			t.Assert().True(errors.Is(err, context.DeadlineExceeded), "错误应该包含 context.DeadlineExceeded")
+			t.Assert().False(errors.Is(err, client.ErrRequestCanceled), "超时不应该被识别为取消")
+		})
+	})
+}
+
+// TestRequestWithCanceledContext 测试取消请求
+func TestRequestWithCanceledContext(t *testing.T) {
+	runTest(t, "Request with canceled context", func(t provider.T) {
+		t.Tags("api", "context", "negative")
+		t.Description("验证被取消的请求返回可区分的取消错误")
+		t.Severity(allure.NORMAL)
+
+		apiClient := newTestClient(t)
+		cfg := config.GetConfig()
+		var err error
+
+		t.WithNewStep("使用已取消的 context 登录", func(sCtx provider.StepCtx) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, _, err = apiClient.LoginWithContext(ctx, models.LoginRequest{
+				Username: cfg.Auth.Username,
+				Password: cfg.Auth.Password,
+			})
+		})
+
+		t.WithNewStep("验证取消错误", func(sCtx provider.StepCtx) {
+			sCtx.Logf("返回的错误: %v", err)
+			t.Require().Error(err, "被取消的请求应该返回错误")
+			t.Assert().True(errors.Is(err, client.ErrRequestCanceled), "错误应该是 ErrRequestCanceled")
+			t.Assert().True(errors.Is(err, context.Canceled), "错误应该包含 context.Canceled")
+		})
+	})
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/models"
+	"go-testify-allure-api-test/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// driftProducts 与 models.Product 不一致的商品列表：多出 brand，第二个商品缺少 rating，评分多出 votes
+const driftProducts = `[
+	{"id":1,"title":"A","price":1,"description":"","category":"c","image":"","brand":"x","rating":{"rate":4.5,"count":3,"votes":1}},
+	{"id":2,"title":"B","price":2,"description":"","category":"c","image":"","brand":"y"}
+]`
+
+// TestStrictDecoding 测试严格解码模式报告响应与模型的差异
+func TestStrictDecoding(t *testing.T) {
+	runTest(t, "Strict decoding", func(t provider.T) {
+		t.Tags("client", "drift")
+		t.Description("验证严格解码模式发现未知字段和缺少的必填字段，report 模式只记录报告，fail 模式返回 DriftError")
+		t.Severity(allure.NORMAL)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/products":
+				w.Write([]byte(driftProducts))
+			default:
+				w.Write([]byte(`{"id":1,"userId":1,"date":"2020-03-02T00:00:00.000Z","products":[{"productId":1,"quantity":2}]}`))
+			}
+		}))
+		t.Cleanup(server.Close)
+		reporter, ok := t.(*utils.AllureReporter)
+		t.Require().True(ok, "runTest 应该传入 AllureReporter")
+		newClient := func(mode client.StrictMode) *client.APIClient {
+			return client.New(nil,
+				client.WithBaseURL(server.URL),
+				client.WithStrictDecoding(mode),
+				client.WithHook(reporter.Hook),
+			)
+		}
+
+		t.WithNewStep("CheckDrift 按路径报告差异", func(sCtx provider.StepCtx) {
+			drifts := client.CheckDrift([]byte(driftProducts), &[]models.Product{})
+			t.Assert().Equal([]client.Drift{
+				{Kind: client.DriftUnknown, Path: "$[*].brand"},
+				{Kind: client.DriftMissing, Path: "$[*].rating"},
+				{Kind: client.DriftUnknown, Path: "$[*].rating.votes"},
+			}, drifts)
+
+			t.Assert().Empty(client.CheckDrift([]byte(`{"ID":1,"userId":1,"date":"2020-03-02T00:00:00Z","products":[]}`), &models.Cart{}),
+				"字段名不区分大小写，time.Time 不展开")
+			t.Assert().Empty(client.CheckDrift([]byte(`{}`), &models.ErrorResponse{}),
+				"没有 jsonschema required 标签的字段不是必填")
+		})
+
+		t.WithNewStep("report 模式记录差异但不返回错误", func(sCtx provider.StepCtx) {
+			products, resp, err := newClient(client.StrictReport).GetAllProducts()
+			t.Require().NoError(err, "report 模式不应该返回错误")
+			t.Assert().Len(products, 2, "响应应该正常解码")
+
+			drift := client.Attempts(resp)[0].Drift
+			t.Require().NotNil(drift, "尝试中应该记录模型差异")
+			t.Assert().Equal("[]models.Product", drift.Model)
+			t.Assert().Equal([]string{"$[*].brand", "$[*].rating.votes"}, drift.Paths(client.DriftUnknown))
+			t.Assert().Equal([]string{"$[*].rating"}, drift.Paths(client.DriftMissing))
+
+			var attachment *allure.Attachment
+			for _, a := range sCtx.CurrentStep().Attachments {
+				if strings.HasSuffix(a.Name, " 模型差异") {
+					attachment = a
+				}
+			}
+			t.Require().NotNil(attachment, "应该附加模型差异报告")
+			var report client.DriftReport
+			t.Require().NoError(json.Unmarshal(attachment.GetContent(), &report))
+			t.Assert().Equal(drift.Drifts, report.Drifts)
+		})
+
+		t.WithNewStep("fail 模式返回 DriftError", func(sCtx provider.StepCtx) {
+			products, _, err := newClient(client.StrictFail).GetAllProducts()
+			var driftErr *client.DriftError
+			t.Require().True(errors.As(err, &driftErr), "应该返回 DriftError: %v", err)
+			t.Assert().Equal("/products", driftErr.Report.URL)
+			t.Assert().Contains(err.Error(), "$[*].brand")
+			t.Assert().Len(products, 2, "结果仍然解码")
+		})
+
+		t.WithNewStep("一致的响应和 off 模式不产生报告", func(sCtx provider.StepCtx) {
+			_, resp, err := newClient(client.StrictFail).GetCartByID(1)
+			t.Require().NoError(err)
+			t.Assert().Nil(client.Attempts(resp)[0].Drift)
+
+			_, resp, err = newClient(client.StrictOff).GetAllProducts()
+			t.Require().NoError(err)
+			t.Assert().Nil(client.Attempts(resp)[0].Drift)
+		})
+	})
+}
@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/config"
+	"go-testify-allure-api-test/fakestore"
+	"go-testify-allure-api-test/models"
+	"go-testify-allure-api-test/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestFaultInjection 测试故障注入以及客户端在故障下的重试、超时和解码行为
+func TestFaultInjection(t *testing.T) {
+	runTest(t, "Fault injection", func(t provider.T) {
+		t.Tags("client", "fault-injection")
+		t.Description("验证按接口注入状态码、连接重置、超时、延迟、截断和损坏的响应体，以及重试策略对这些故障的处理")
+		t.Severity(allure.NORMAL)
+
+		server := fakestore.NewServer()
+		t.Cleanup(server.Close)
+
+		reporter, ok := t.(*utils.AllureReporter)
+		t.Require().True(ok, "runTest 应该传入 AllureReporter")
+		policy := client.RetryPolicy{
+			Count:          2,
+			Statuses:       []int{429, 500, 503},
+			NetworkErrors:  true,
+			WaitTime:       10 * time.Millisecond,
+			MaxWaitTime:    2 * time.Second,
+			RetryAfter:     true,
+			ExcludeMethods: []string{"POST", "PATCH"},
+		}
+		injector := client.NewFaultInjector()
+		apiClient := client.New(nil,
+			client.WithBaseURL(server.URL()),
+			client.WithRetryPolicy(policy),
+			client.WithFaults(injector),
+			client.WithBreaker(nil),
+			client.WithLimiter(nil),
+			client.WithTimeout(300*time.Millisecond),
+			client.WithHook(reporter.Hook),
+		)
+		reset := func() {
+			injector.Clear()
+		}
+
+		t.WithNewStep("503后重试成功", func(sCtx provider.StepCtx) {
+			defer reset()
+			injector.Add(client.FaultRule{Method: "GET", Path: "/products", Fault: client.FaultStatus, Status: 503, Times: 1})
+
+			products, resp, err := apiClient.GetAllProducts()
+			t.Require().NoError(err, "第二次尝试应该成功")
+			t.Assert().NotEmpty(products)
+			attempts := client.Attempts(resp)
+			t.Require().Len(attempts, 2)
+			t.Assert().Equal(503, attempts[0].StatusCode)
+			t.Assert().Equal(200, attempts[1].StatusCode)
+		})
+
+		t.WithNewStep("429按 Retry-After 等待", func(sCtx provider.StepCtx) {
+			defer reset()
+			injector.Add(client.FaultRule{Path: "/products", Fault: client.FaultStatus, Status: 429, RetryAfter: time.Second, Times: 1})
+
+			_, resp, err := apiClient.GetAllProducts()
+			t.Require().NoError(err)
+			attempts := client.Attempts(resp)
+			t.Require().Len(attempts, 2)
+			t.Assert().Equal(time.Second, attempts[0].Wait, "应该按 Retry-After 等待")
+		})
+
+		t.WithNewStep("重试次数用尽后返回500", func(sCtx provider.StepCtx) {
+			defer reset()
+			injector.Add(client.FaultRule{Path: "/products/*", Fault: client.FaultStatus, Status: 500})
+
+			_, resp, err := apiClient.GetProductByID(1)
+			t.Require().Error(err)
+			t.Assert().Equal(500, resp.StatusCode())
+			t.Assert().Equal("status", resp.Header().Get("X-Fault-Injected"))
+			t.Assert().Len(client.Attempts(resp), 3, "应该尝试 1+Count 次")
+
+			_, _, err = apiClient.GetAllProducts()
+			t.Assert().NoError(err, "不匹配的接口不应该注入故障")
+		})
+
+		t.WithNewStep("非幂等方法遇到连接重置不重试", func(sCtx provider.StepCtx) {
+			defer reset()
+			injector.Add(client.FaultRule{Method: "*", Path: "/carts*", Fault: client.FaultReset})
+
+			_, resp, err := apiClient.GetAllCarts()
+			t.Require().Error(err)
+			t.Assert().True(errors.Is(err, syscall.ECONNRESET), "应该返回连接被重置: %v", err)
+			t.Assert().Len(client.Attempts(resp), 3, "GET 应该重试")
+
+			before := injector.Injected()
+			_, _, err = apiClient.CreateCart(models.CreateCartRequest{UserID: 1})
+			t.Require().Error(err)
+			t.Assert().Equal(before+1, injector.Injected(), "POST 不应该重试")
+		})
+
+		t.WithNewStep("超时", func(sCtx provider.StepCtx) {
+			defer reset()
+			injector.Add(client.FaultRule{Path: "/products/categories", Fault: client.FaultTimeout})
+
+			start := time.Now()
+			_, _, err := apiClient.GetAllCategoriesWithContext(context.Background())
+			t.Require().Error(err)
+			sCtx.Logf("超时错误: %v", err)
+			t.Assert().Less(time.Since(start), 2*time.Second, "应该在客户端超时后返回")
+		})
+
+		t.WithNewStep("延迟", func(sCtx provider.StepCtx) {
+			defer reset()
+			injector.Add(client.FaultRule{Path: "/products/categories", Fault: client.FaultLatency, Latency: 100 * time.Millisecond})
+
+			categories, resp, err := apiClient.GetAllCategories()
+			t.Require().NoError(err)
+			t.Assert().NotEmpty(categories)
+			t.Assert().GreaterOrEqual(resp.Time(), 100*time.Millisecond, "响应时间应该包含注入的延迟")
+		})
+
+		t.WithNewStep("响应体被截断", func(sCtx provider.StepCtx) {
+			defer reset()
+			injector.Add(client.FaultRule{Path: "/products", Fault: client.FaultTruncate, Times: 1})
+
+			_, _, err := apiClient.GetAllProducts()
+			t.Require().Error(err)
+			t.Assert().True(errors.Is(err, io.ErrUnexpectedEOF), "应该返回 io.ErrUnexpectedEOF: %v", err)
+		})
+
+		t.WithNewStep("响应体不是完整的 JSON", func(sCtx provider.StepCtx) {
+			defer reset()
+			injector.Add(client.FaultRule{Path: "/products", Fault: client.FaultMalformed})
+
+			_, resp, err := apiClient.GetAllProducts()
+			t.Require().Error(err, "解码损坏的 JSON 应该返回错误")
+			sCtx.Logf("解码错误: %v", err)
+			t.Assert().Equal(http.StatusOK, resp.StatusCode())
+			t.Assert().Equal("malformed", resp.Header().Get("X-Fault-Injected"))
+		})
+
+		t.WithNewStep("从配置读取规则", func(sCtx provider.StepCtx) {
+			cfg, err := config.Resolve(config.Overrides{
+				Env: fakeEnv(map[string]string{
+					"APITEST_FAULT_INJECTION_ENABLED": "true",
+					"APITEST_FAULT_INJECTION_RULES":   "GET /products/* fault=status status=503 times=1,* /carts fault=reset probability=0.5",
+				}),
+			})
+			t.Require().NoError(err)
+			t.Require().NoError(cfg.Validate())
+			rules, err := cfg.FaultRules()
+			t.Require().NoError(err)
+			t.Require().Len(rules, 2)
+			t.Assert().Equal(config.FaultRule{Method: "GET", Path: "/products/*", Fault: "status", Status: 503, Probability: 1, Times: 1}, rules[0])
+			t.Assert().Equal(0.5, rules[1].Probability)
+			t.Assert().NotNil(client.NewFaultInjectorFromConfig(cfg))
+
+			cfg, err = config.Resolve(config.Overrides{
+				Env: fakeEnv(map[string]string{
+					"APITEST_FAULT_INJECTION_RULES": "GET /products fault=status status=200,GET /carts fault=explode",
+				}),
+			})
+			t.Require().NoError(err)
+			err = cfg.Validate()
+			t.Require().Error(err, "无效的规则应该校验失败")
+			t.Assert().Contains(err.Error(), "status 200")
+			t.Assert().Contains(err.Error(), `fault "explode"`)
+		})
+
+		t.WithNewStep("按比例注入", func(sCtx provider.StepCtx) {
+			defer reset()
+			injector.Add(client.FaultRule{Path: "/products/categories", Fault: client.FaultStatus, Status: 503, Probability: 0.5})
+
+			noRetry := client.New(nil,
+				client.WithBaseURL(server.URL()),
+				client.WithFaults(injector),
+				client.WithBreaker(nil),
+				client.WithRetry(0, 0, 0),
+			)
+			const requests = 200
+			before := injector.Injected()
+			for i := 0; i < requests; i++ {
+				noRetry.GetAllCategories()
+			}
+			injected := injector.Injected() - before
+			t.Assert().Greater(injected, requests/4, "注入次数应该接近一半")
+			t.Assert().Less(injected, requests*3/4, "注入次数应该接近一半")
+		})
+
+		t.WithNewStep("省略比例的规则每次都注入，配置中比例为0的规则从不注入", func(sCtx provider.StepCtx) {
+			defer reset()
+			injector.Add(client.FaultRule{Path: "/products/categories", Fault: client.FaultStatus, Status: 503})
+
+			noRetry := client.New(nil,
+				client.WithBaseURL(server.URL()),
+				client.WithFaults(injector),
+				client.WithBreaker(nil),
+				client.WithRetry(0, 0, 0),
+			)
+			before := injector.Injected()
+			for i := 0; i < 50; i++ {
+				_, _, err := noRetry.GetAllCategories()
+				t.Require().Error(err, "每个请求都应该被注入503")
+			}
+			t.Assert().Equal(before+50, injector.Injected(), "省略 Probability 时每次都应该注入")
+
+			cfg, err := config.Resolve(config.Overrides{
+				Env: fakeEnv(map[string]string{
+					"APITEST_FAULT_INJECTION_ENABLED": "true",
+					"APITEST_FAULT_INJECTION_RULES":   "GET /products/categories fault=status status=503 probability=0",
+				}),
+			})
+			t.Require().NoError(err)
+			t.Require().NoError(cfg.Validate())
+			fromConfig := client.NewFaultInjectorFromConfig(cfg)
+			t.Require().Nil(fromConfig, "只有 probability=0 的规则时不应该创建 FaultInjector")
+			configured := client.New(nil,
+				client.WithBaseURL(server.URL()),
+				client.WithFaults(fromConfig),
+				client.WithBreaker(nil),
+				client.WithRetry(0, 0, 0),
+			)
+			for i := 0; i < 50; i++ {
+				_, _, err := configured.GetAllCategories()
+				t.Require().NoError(err)
+			}
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/fakestore"
+	"go-testify-allure-api-test/har"
+	"go-testify-allure-api-test/models"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestHARRecorder 测试 HAR 记录器
+func TestHARRecorder(t *testing.T) {
+	runTest(t, "HAR recorder", func(t provider.T) {
+		t.Tags("client", "har")
+		t.Description("验证每次请求尝试（包括网络错误后的重试）都记录为 HAR 1.2 条目并写入文件")
+		t.Severity(allure.NORMAL)
+
+		// 第一次请求直接断开连接，第二次正常返回
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		}))
+		t.Cleanup(server.Close)
+
+		recorder := har.NewRecorder()
+		apiClient := client.New(nil,
+			client.WithBaseURL(server.URL),
+			client.WithRetry(1, time.Millisecond, time.Millisecond),
+			client.WithHook(recorder.Hook),
+		)
+		apiClient.SetAuthToken("secret-token")
+
+		var file har.File
+
+		t.WithNewStep("发送会被重试的请求并写入 HAR 文件", func(sCtx provider.StepCtx) {
+			_, _, err := apiClient.GetProductsByLimit(3)
+			t.Require().NoError(err, "重试后请求应该成功")
+
+			dir, err := os.MkdirTemp("", "apitest-har")
+			t.Require().NoError(err)
+			t.Cleanup(func() { os.RemoveAll(dir) })
+
+			path := filepath.Join(dir, "run.har")
+			t.Require().NoError(recorder.WriteFile(path, "har test"), "写入 HAR 文件不应该返回错误")
+			data, err := os.ReadFile(path)
+			t.Require().NoError(err)
+			sCtx.WithNewAttachment("run.har", allure.JSON, data)
+			t.Require().NoError(json.Unmarshal(data, &file), "HAR 文件应该是有效的 JSON")
+		})
+
+		t.WithNewStep("验证 HAR 内容", func(sCtx provider.StepCtx) {
+			t.Assert().Equal("1.2", file.Log.Version)
+			t.Require().Len(file.Log.Entries, 2, "两次尝试应该各有一条记录")
+
+			failed, retried := file.Log.Entries[0], file.Log.Entries[1]
+			t.Assert().Equal(1, failed.Attempt)
+			t.Assert().Equal(0, failed.Response.Status, "网络错误的尝试没有响应")
+			t.Assert().NotEmpty(failed.Error, "网络错误的尝试应该记录错误")
+
+			t.Assert().Equal(2, retried.Attempt)
+			t.Assert().Equal(200, retried.Response.Status)
+			t.Assert().Equal("[]", retried.Response.Content.Text)
+			t.Assert().Equal(server.URL+"/products?limit=3", retried.Request.URL)
+			t.Assert().Equal([]har.NameValue{{Name: "limit", Value: "3"}}, retried.Request.QueryString)
+			t.Assert().Contains(retried.Request.Headers, har.NameValue{Name: "Authorization", Value: "******"}, "敏感请求头应该被隐藏")
+			t.Assert().GreaterOrEqual(retried.Timings.Wait, 0.0, "应该记录等待时间")
+			t.Assert().Equal("127.0.0.1", retried.ServerIPAddress)
+		})
+
+		t.WithNewStep("请求体中的密码被隐藏", func(sCtx provider.StepCtx) {
+			store := fakestore.NewServer()
+			t.Cleanup(store.Close)
+			login := har.NewRecorder()
+			_, _, err := client.New(nil, client.WithBaseURL(store.URL()), client.WithHook(login.Hook)).
+				Login(models.LoginRequest{Username: "mor_2314", Password: "83r5^_"})
+			t.Require().NoError(err, "登录不应该返回错误")
+
+			entries := login.File("").Log.Entries
+			t.Require().Len(entries, 1)
+			t.Require().NotNil(entries[0].Request.PostData)
+			t.Assert().Equal(`{"username":"mor_2314","password":"******"}`, entries[0].Request.PostData.Text)
+		})
+
+		t.WithNewStep("按时间而不是字符串排序", func(sCtx provider.StepCtx) {
+			// 按字符串比较时 "…:00Z" 排在 "…:00.5Z" 之后，带时区偏移的时间也无法按字符串比较
+			base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			sorted := har.NewRecorder()
+			sorted.Add(har.Entry{StartedDateTime: base.Add(500 * time.Millisecond), Comment: "third"})
+			sorted.Add(har.Entry{StartedDateTime: base, Comment: "second"})
+			sorted.Add(har.Entry{StartedDateTime: base.Add(-time.Minute).In(time.FixedZone("CST", 8*3600)), Comment: "first"})
+
+			var comments []string
+			for _, entry := range sorted.File("").Log.Entries {
+				comments = append(comments, entry.Comment)
+			}
+			t.Assert().Equal([]string{"first", "second", "third"}, comments)
+
+			data, err := json.Marshal(sorted.File("").Log.Entries[1])
+			t.Require().NoError(err)
+			t.Assert().Contains(string(data), `"startedDateTime":"2024-01-01T12:00:00Z"`)
+		})
+	})
+}
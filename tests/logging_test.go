@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/config"
+	"go-testify-allure-api-test/fakestore"
+	"go-testify-allure-api-test/logging"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestStructuredRequestLogging 测试客户端请求的结构化日志
+func TestStructuredRequestLogging(t *testing.T) {
+	runTest(t, "Structured request logging", func(t provider.T) {
+		t.Tags("client", "logging")
+		t.Description("验证按 logging 配置写入 JSON 日志文件，每个请求和响应都带有结构化字段")
+		t.Severity(allure.NORMAL)
+
+		server := fakestore.NewServer()
+		t.Cleanup(server.Close)
+
+		dir, err := os.MkdirTemp("", "apitest-logging")
+		t.Require().NoError(err, "创建临时目录不应该返回错误")
+		t.Cleanup(func() { os.RemoveAll(dir) })
+		logFile := filepath.Join(dir, "logs", "api-test.log")
+
+		t.WithNewStep("按配置创建写入文件的 JSON 日志记录器并发送请求", func(sCtx provider.StepCtx) {
+			cfg, err := config.Load("", config.WithoutEnv(), config.WithFlags(map[string]string{
+				"api.base_url":   server.URL(),
+				"logging.level":  "debug",
+				"logging.format": "json",
+				"logging.output": "file",
+				"logging.file":   logFile,
+			}))
+			t.Require().NoError(err, "加载配置不应该返回错误")
+
+			logger, closeLog, err := logging.New(cfg)
+			t.Require().NoError(err, "创建日志记录器不应该返回错误")
+
+			apiClient := client.New(cfg, client.WithLogger(logger), client.WithTestName(t.Name()))
+			_, _, err = apiClient.GetProductByID(1)
+			t.Require().NoError(err, "请求不应该返回错误")
+			_, _, err = apiClient.GetProductByID(999)
+			t.Require().Error(err, "不存在的商品应该返回错误")
+			t.Require().NoError(closeLog(), "关闭日志文件不应该返回错误")
+		})
+
+		t.WithNewStep("验证日志字段", func(sCtx provider.StepCtx) {
+			content, err := os.ReadFile(logFile)
+			t.Require().NoError(err, "日志文件应该存在")
+			sCtx.WithNewAttachment("api-test.log", allure.Text, content)
+
+			f, err := os.Open(logFile)
+			t.Require().NoError(err)
+			defer f.Close()
+
+			var entries []map[string]interface{}
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				var entry map[string]interface{}
+				t.Require().NoError(json.Unmarshal(scanner.Bytes(), &entry), "每行日志都应该是有效的JSON")
+				entries = append(entries, entry)
+			}
+			t.Require().Len(entries, 4, "两个请求应该各有一条请求日志和一条响应日志")
+
+			t.Assert().Equal("http request", entries[0]["msg"])
+			t.Assert().Equal("DEBUG", entries[0]["level"])
+			t.Assert().Equal("GET", entries[0]["method"])
+			t.Assert().Equal("/products/1", entries[0]["path"])
+
+			ok := entries[1]
+			t.Assert().Equal("http response", ok["msg"])
+			t.Assert().Equal("INFO", ok["level"])
+			t.Assert().Equal("/products/1", ok["path"])
+			t.Assert().EqualValues(200, ok["status"])
+			t.Assert().EqualValues(1, ok["attempt"])
+			t.Assert().Contains(ok, "duration", "响应日志应该包含耗时")
+			t.Assert().Equal(t.Name(), ok["test"], "响应日志应该包含测试名称")
+
+			notFound := entries[3]
+			t.Assert().Equal("WARN", notFound["level"], "4xx响应应该记录为警告")
+			t.Assert().EqualValues(404, notFound["status"])
+			t.Assert().Equal("/products/999", notFound["path"])
+		})
+	})
+}
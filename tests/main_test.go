@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"go-testify-allure-api-test/config"
+	"go-testify-allure-api-test/fakestore"
+	"go-testify-allure-api-test/har"
+	"go-testify-allure-api-test/openapi"
+	"go-testify-allure-api-test/preflight"
+	"go-testify-allure-api-test/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+)
+
+// fakeStoreEnv 设置为 true 时测试改为请求进程内的 Fake Store 替身服务
+const fakeStoreEnv = "APITEST_FAKESTORE"
+
+// harScopeTest 和 harScopeRun 对应 har.scope 的取值
+const (
+	harScopeTest = "test"
+	harScopeRun  = "run"
+)
+
+// runHARFile har.scope 为 run 时整次运行的 HAR 文件名
+const runHARFile = "api-traffic.har"
+
+// preflightFile 预检诊断报告的文件名
+const preflightFile = "preflight.txt"
+
+// contractFile 整次运行的 OpenAPI 契约校验汇总的文件名
+const contractFile = "openapi-summary.txt"
+
+var (
+	// offline 是否使用离线替身服务，在 TestMain 中设置后只读
+	offline bool
+	// runHAR har.scope 为 run 时所有用例共享的记录器，否则为nil
+	runHAR *har.Recorder
+	// contract openapi.enabled 为 true 时加载的契约，否则为nil
+	contract *openapi.Document
+	// runContract 汇总所有用例的契约校验结果，contract 为nil时为nil
+	runContract *openapi.Checker
+	// preflightFailure 预检失败且 preflight.on_failure 为 skip 时的失败原因，runTest 据此跳过每个用例
+	preflightFailure string
+)
+
+// TestMain 测试入口，按需启动离线替身服务
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+// run 执行测试并返回退出码，保证 defer 在 os.Exit 之前执行
+func run(m *testing.M) int {
+	// 先注册并解析命令行参数，使 go test ./tests -args -apitest.api.base_url=... 在加载配置前生效
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg := config.GetConfig()
+	if cfg.Test.Verbose {
+		log.Printf("生效配置:\n%s", cfg.Report())
+	}
+
+	offline, _ = strconv.ParseBool(os.Getenv(fakeStoreEnv))
+	if offline {
+		server := fakestore.NewServer()
+		defer server.Close()
+
+		cfg.API.BaseURL = server.URL()
+		log.Printf("使用离线 Fake Store 替身服务: %s", server.URL())
+	}
+
+	utils.ConfigureAllure(cfg)
+	if err := utils.WriteAllureEnvironment(cfg); err != nil {
+		log.Printf("Warning: Could not write Allure environment: %v", err)
+	}
+
+	// 回放磁带时不需要访问目标服务
+	if cfg.Preflight.Enabled && cfg.VCR.Mode != "replay" {
+		if code, ok := runPreflight(cfg); !ok {
+			return code
+		}
+	}
+
+	if cfg.OpenAPI.Enabled {
+		doc, err := openapi.Load(cfg.OpenAPI.Spec)
+		if err != nil {
+			log.Printf("加载 OpenAPI 契约失败: %v", err)
+			return 1
+		}
+		contract = doc
+		runContract = openapi.NewChecker(doc)
+	}
+
+	if cfg.HAR.Enabled && cfg.HAR.Scope == harScopeRun {
+		runHAR = har.NewRecorder()
+	}
+	code := m.Run()
+	if runHAR != nil {
+		path := filepath.Join(utils.AllureResultsPath(), runHARFile)
+		if err := runHAR.WriteFile(path, "test run"); err != nil {
+			log.Printf("Warning: Could not write HAR file %s: %v", path, err)
+		}
+	}
+	if runContract != nil {
+		writeContractSummary(cfg)
+	}
+	return code
+}
+
+// writeContractSummary 把整次运行的契约校验汇总写入结果目录，并在 Allure 中记录为独立的结果：
+// 有违规且 openapi.mode 为 fail 时为 failed，否则为 passed
+func writeContractSummary(cfg *config.Config) {
+	summary := runContract.Summary()
+	path := filepath.Join(utils.AllureResultsPath(), contractFile)
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err == nil {
+		err = os.WriteFile(path, []byte(summary), 0644)
+	}
+	if err != nil {
+		log.Printf("Warning: Could not write OpenAPI summary %s: %v", path, err)
+	}
+
+	status, message := allure.Passed, fmt.Sprintf("校验请求 %d 个，未发现违规", runContract.Checked())
+	if failures := len(runContract.Failures()); failures > 0 {
+		message = fmt.Sprintf("校验请求 %d 个，发现 %d 处违规", runContract.Checked(), failures)
+		if cfg.OpenAPI.Mode == "fail" {
+			status = allure.Failed
+		}
+		log.Printf("OpenAPI 契约校验: %s，详见 %s", message, path)
+	}
+	if err := utils.WriteResult("OpenAPI conformance", "Contract", status, message, summary); err != nil {
+		log.Printf("Warning: Could not write Allure result: %v", err)
+	}
+}
+
+// runPreflight 检查目标服务是否可用并把诊断报告写入结果目录。
+// 检查失败且 preflight.on_failure 不为 warn 时在 Allure 中记录一个 broken 结果：skip 时设置 preflightFailure，
+// 用例照常启动并逐个跳过，go test 的输出中可以看到跳过的原因；abort 时返回 ok 为 false 和退出码1，不执行用例
+func runPreflight(cfg *config.Config) (code int, ok bool) {
+	report := preflight.Run(context.Background(), preflight.FromConfig(cfg))
+	text := report.String()
+
+	path := filepath.Join(utils.AllureResultsPath(), preflightFile)
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err == nil {
+		err = os.WriteFile(path, []byte(text), 0644)
+	}
+	if err != nil {
+		log.Printf("Warning: Could not write pre-flight report %s: %v", path, err)
+	}
+
+	if report.OK() {
+		if cfg.Test.Verbose {
+			log.Printf("预检通过:\n%s", text)
+		}
+		return 0, true
+	}
+
+	reason := report.Reason()
+	log.Printf("%s\n%s", reason, text)
+	if cfg.Preflight.OnFailure == "warn" {
+		log.Printf("Warning: preflight.on_failure 为 warn，继续执行用例")
+		return 0, true
+	}
+
+	if err := utils.WriteBrokenResult("Pre-flight check", reason, text); err != nil {
+		log.Printf("Warning: Could not write Allure result: %v", err)
+	}
+	if cfg.Preflight.OnFailure == "abort" {
+		log.Printf("目标服务不可用，终止测试")
+		return 1, false
+	}
+	log.Printf("目标服务不可用，跳过所有用例")
+	preflightFailure = reason
+	return 0, true
+}
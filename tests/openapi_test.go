@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/fakestore"
+	"go-testify-allure-api-test/models"
+	"go-testify-allure-api-test/openapi"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// contractSpec 测试使用的 OpenAPI 契约，路径相对于 tests 目录
+const contractSpec = "testdata/openapi.yaml"
+
+// TestOpenAPIContract 测试按 OpenAPI 契约校验请求和响应
+func TestOpenAPIContract(t *testing.T) {
+	runTest(t, "OpenAPI contract conformance", func(t provider.T) {
+		t.Tags("contract", "openapi")
+		t.Description("加载 OpenAPI 3 契约，验证替身服务符合契约，偏离契约的状态码、内容类型、响应体和路径被报告为违规")
+		t.Severity(allure.CRITICAL)
+
+		doc, err := openapi.Load(contractSpec)
+		t.Require().NoError(err, "契约应该能加载")
+
+		t.WithNewStep("加载契约", func(sCtx provider.StepCtx) {
+			ops := doc.Operations()
+			t.Assert().Len(ops, 22)
+			t.Assert().Contains(ops, "GET /products/{id}")
+			t.Assert().Contains(ops, "POST /auth/login")
+
+			_, err := openapi.Parse([]byte(`swagger: "2.0"`))
+			t.Require().Error(err)
+			t.Assert().Contains(err.Error(), "不支持的 OpenAPI 版本")
+			_, err = openapi.Parse([]byte(`
+openapi: "3.0.3"
+paths:
+  /products:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema: { $ref: "#/components/schemas/Missing" }
+`))
+			t.Require().Error(err)
+			t.Assert().Contains(err.Error(), "无法解析 #/components/schemas/Missing")
+		})
+
+		t.WithNewStep("替身服务符合契约", func(sCtx provider.StepCtx) {
+			server := fakestore.NewServer()
+			t.Cleanup(server.Close)
+			checker := openapi.NewChecker(doc)
+			apiClient := client.New(nil,
+				client.WithBaseURL(server.URL()),
+				client.WithTransport(checker.Transport(nil)),
+				client.WithRetry(0, 0, 0),
+			)
+
+			_, _, err := apiClient.GetProductsByLimit(2)
+			t.Require().NoError(err)
+			_, _, err = apiClient.GetProductByID(1)
+			t.Require().NoError(err)
+			_, _, err = apiClient.CreateCart(models.CreateCartRequest{UserID: 1, Date: time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC), Products: []models.CartProduct{{ProductID: 1, Quantity: 2}}})
+			t.Require().NoError(err)
+			_, _, err = apiClient.GetProductsBySort("sideways")
+			t.Require().Error(err, "无效的排序方式应该被服务端拒绝")
+			sCtx.WithNewAttachment("summary", allure.Text, []byte(checker.Summary()))
+
+			t.Assert().Equal(4, checker.Checked())
+			t.Assert().Empty(checker.Failures(), "替身服务不应该违反契约")
+			violations := checker.Violations()
+			t.Require().Len(violations, 1, "无效的查询参数应该被报告")
+			t.Assert().Equal(openapi.PhaseRequest, violations[0].Phase)
+			t.Assert().Equal("query.sort", violations[0].Location)
+			t.Assert().Equal(http.StatusBadRequest, violations[0].Status)
+			t.Assert().True(violations[0].Expected(), "被服务端以400拒绝的请求违规是预期的")
+		})
+
+		t.WithNewStep("偏离契约的服务被报告", func(sCtx provider.StepCtx) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/products":
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(invalidProducts))
+				case "/products/categories":
+					w.Header().Set("Content-Type", "text/html")
+					w.Write([]byte("<ul><li>electronics</li></ul>"))
+				case "/orders":
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte("[]"))
+				default:
+					w.WriteHeader(http.StatusTeapot)
+				}
+			}))
+			t.Cleanup(server.Close)
+			checker := openapi.NewChecker(doc)
+			apiClient := client.New(nil,
+				client.WithBaseURL(server.URL),
+				client.WithTransport(checker.Transport(nil)),
+				client.WithRetry(0, 0, 0),
+			)
+
+			apiClient.GetAllProducts()
+			apiClient.GetAllCategories()
+			apiClient.GetProductByID(1)
+			resp, err := (&http.Client{Transport: checker.Transport(nil)}).Get(server.URL + "/orders")
+			t.Require().NoError(err)
+			resp.Body.Close()
+			summary := checker.Summary()
+			sCtx.WithNewAttachment("summary", allure.Text, []byte(summary))
+
+			got := make(map[string]bool)
+			for _, v := range checker.Failures() {
+				got[v.Operation+" "+v.Location+v.Pointer] = true
+			}
+			for _, want := range []string{
+				"GET /products body/0/price",
+				"GET /products body/0/rating/rate",
+				"GET /products body/1/rating",
+				"GET /products/categories content-type",
+				"GET /products/{id} status",
+				" path",
+			} {
+				t.Assert().True(got[want], "应该报告 %q，实际 %v", want, got)
+			}
+
+			t.Assert().Contains(summary, "校验请求: 4")
+			t.Assert().Contains(summary, "GET /orders（未声明）")
+			t.Assert().Contains(summary, "✗ GET /products/1 → 418 response status: 状态码 418 未在契约中声明")
+			t.Assert().Contains(summary, "未覆盖的操作 (19)")
+		})
+
+		t.WithNewStep("未声明的方法被报告", func(sCtx provider.StepCtx) {
+			req, err := http.NewRequest(http.MethodDelete, "https://fakestoreapi.com/products/categories", nil)
+			t.Require().NoError(err)
+			violations := doc.ValidateRequest(req, nil)
+			t.Require().Len(violations, 1)
+			t.Assert().Equal("method", violations[0].Location)
+			t.Assert().Contains(violations[0].Message, "已声明 GET")
+		})
+	})
+}
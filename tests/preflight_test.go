@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/config"
+	"go-testify-allure-api-test/preflight"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestPreflight 测试运行前的目标服务检查
+func TestPreflight(t *testing.T) {
+	runTest(t, "Pre-flight check", func(t provider.T) {
+		t.Tags("preflight")
+		t.Description("验证预检按 DNS、TCP、TLS、HTTP 的顺序检查目标服务，并在第一项失败时给出一行原因")
+		t.Severity(allure.NORMAL)
+
+		cfg := config.GetConfig()
+		options := func(baseURL string) preflight.Options {
+			return preflight.Options{
+				BaseURL:     baseURL,
+				Endpoints:   []string{"/products?limit=1"},
+				ContentType: "application/json",
+				Timeout:     2 * time.Second,
+			}
+		}
+
+		t.WithNewStep("目标服务正常", func(sCtx provider.StepCtx) {
+			report := preflight.Run(context.Background(), preflight.FromConfig(cfg))
+			sCtx.WithNewAttachment("Pre-flight report", allure.Text, []byte(report.String()))
+			t.Require().True(report.OK(), report.Reason())
+			t.Assert().Empty(report.Reason())
+			last := report.Checks[len(report.Checks)-1]
+			t.Assert().Equal(preflight.CheckHTTP, last.Name, "最后一项应该是 HTTP 检查")
+		})
+
+		t.WithNewStep("响应类型不符", func(sCtx provider.StepCtx) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.Write([]byte("<html>maintenance</html>"))
+			}))
+			defer server.Close()
+
+			report := preflight.Run(context.Background(), options(server.URL))
+			sCtx.WithNewAttachment("Pre-flight report", allure.Text, []byte(report.String()))
+			t.Require().False(report.OK())
+			t.Assert().Equal(preflight.CheckHTTP, report.Failed().Name)
+			t.Assert().Contains(report.Reason(), "text/html")
+		})
+
+		t.WithNewStep("接口返回非2xx", func(sCtx provider.StepCtx) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			report := preflight.Run(context.Background(), options(server.URL))
+			t.Require().False(report.OK())
+			t.Assert().Contains(report.Reason(), "503")
+		})
+
+		t.WithNewStep("端口无法连接", func(sCtx provider.StepCtx) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			t.Require().NoError(err)
+			addr := listener.Addr().String()
+			listener.Close()
+
+			report := preflight.Run(context.Background(), options("http://"+addr))
+			sCtx.WithNewAttachment("Pre-flight report", allure.Text, []byte(report.String()))
+			t.Require().False(report.OK())
+			t.Assert().Equal(preflight.CheckTCP, report.Failed().Name, "IP 地址不需要 DNS 检查，应该在 TCP 检查失败")
+			t.Assert().Len(report.Checks, 2, "失败后不应该继续检查")
+		})
+
+		t.WithNewStep("证书不受信任", func(sCtx provider.StepCtx) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			report := preflight.Run(context.Background(), options(server.URL))
+			sCtx.WithNewAttachment("Pre-flight report", allure.Text, []byte(report.String()))
+			t.Require().False(report.OK())
+			t.Assert().Equal(preflight.CheckTLS, report.Failed().Name)
+		})
+
+		t.WithNewStep("地址无效", func(sCtx provider.StepCtx) {
+			report := preflight.Run(context.Background(), options("fakestoreapi.com"))
+			t.Require().False(report.OK())
+			t.Assert().Equal(preflight.CheckURL, report.Failed().Name)
+		})
+	})
+}
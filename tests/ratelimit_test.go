@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestRateLimiter 测试客户端限速和并发上限
+func TestRateLimiter(t *testing.T) {
+	runTest(t, "Rate limiter", func(t provider.T) {
+		t.Tags("client", "rate-limit")
+		t.Description("验证令牌桶限速和同时请求数上限按主机生效、在客户端之间共享，等待时间记录在尝试和 Allure 附件中")
+		t.Severity(allure.NORMAL)
+
+		// 记录同时处理的最大请求数
+		var inFlight, maxInFlight atomic.Int32
+		limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(30 * time.Millisecond)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		}))
+		t.Cleanup(limited.Close)
+		unlimited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		}))
+		t.Cleanup(unlimited.Close)
+
+		host, err := url.Parse(limited.URL)
+		t.Require().NoError(err)
+		limiter := client.NewLimiter(client.Limit{}, map[string]client.Limit{
+			host.Host: {RPS: 20, Burst: 1, MaxInFlight: 2},
+		})
+		reporter, ok := t.(*utils.AllureReporter)
+		t.Require().True(ok, "runTest 应该传入 AllureReporter")
+		newClient := func(baseURL string) *client.APIClient {
+			return client.New(nil,
+				client.WithBaseURL(baseURL),
+				client.WithLimiter(limiter),
+				client.WithHook(reporter.Hook),
+			)
+		}
+
+		t.WithNewStep("两个客户端共享同一主机的令牌桶", func(sCtx provider.StepCtx) {
+			first, second := newClient(limited.URL), newClient(limited.URL)
+			start := time.Now()
+			var waited time.Duration
+			for i := 0; i < 4; i++ {
+				apiClient := first
+				if i%2 == 1 {
+					apiClient = second
+				}
+				_, resp, err := apiClient.GetAllProducts()
+				t.Require().NoError(err, "请求不应该返回错误")
+				waited += client.Attempts(resp)[0].LimiterWait
+			}
+			// 容量为1、每秒20个令牌：第一个请求立即发送，之后每个请求至少间隔50ms
+			t.Assert().GreaterOrEqual(time.Since(start), 140*time.Millisecond, "请求应该被限速")
+			t.Assert().Greater(waited, time.Duration(0), "应该记录等待限速器的时间")
+
+			found := false
+			for _, a := range sCtx.CurrentStep().Attachments {
+				if strings.HasSuffix(a.Name, " 响应") && strings.Contains(string(a.GetContent()), "Limiter wait: ") {
+					found = true
+				}
+			}
+			t.Assert().True(found, "响应附件应该包含等待限速器的时间")
+		})
+
+		t.WithNewStep("同时进行的请求数不超过上限", func(sCtx provider.StepCtx) {
+			apiClient := newClient(limited.URL)
+			const workers = 6
+			errs := make(chan error, workers)
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, _, err := apiClient.GetAllProducts()
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Assert().NoError(err, "并发请求不应该返回错误")
+			}
+			t.Assert().LessOrEqual(maxInFlight.Load(), int32(2), "同时进行的请求不应该超过 MaxInFlight")
+		})
+
+		t.WithNewStep("其他主机不受限", func(sCtx provider.StepCtx) {
+			apiClient := newClient(unlimited.URL)
+			for i := 0; i < 5; i++ {
+				_, resp, err := apiClient.GetAllProducts()
+				t.Require().NoError(err)
+				t.Assert().Equal(time.Duration(0), client.Attempts(resp)[0].LimiterWait, "未配置的主机不应该等待")
+			}
+		})
+
+		t.WithNewStep("等待名额时取消归还令牌", func(sCtx provider.StepCtx) {
+			// 每秒1个令牌、容量2、同时最多1个请求：第一个请求占住名额，第二个请求取出令牌后等待名额时被取消
+			limiter := client.NewLimiter(client.Limit{RPS: 1, Burst: 2, MaxInFlight: 1}, nil)
+			release, _, err := limiter.Wait(context.Background(), "example.com")
+			t.Require().NoError(err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, _, err = limiter.Wait(ctx, "example.com")
+			t.Require().ErrorIs(err, context.DeadlineExceeded, "等待名额时应该随 ctx 结束")
+			release()
+
+			// 令牌被归还时桶中还有1个令牌，不需要等待
+			ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			release, waited, err := limiter.Wait(ctx, "example.com")
+			t.Require().NoError(err, "取消的请求取出的令牌应该已归还")
+			t.Assert().Less(waited, 100*time.Millisecond)
+			release()
+		})
+	})
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/models"
+	"go-testify-allure-api-test/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestRetryPolicy 测试可配置的重试策略
+func TestRetryPolicy(t *testing.T) {
+	runTest(t, "Retry policy", func(t provider.T) {
+		t.Tags("client", "retry")
+		t.Description("验证按状态码重试、指数退避与抖动、Retry-After、非幂等方法不重试，以及每次尝试记录在 Allure 中")
+		t.Severity(allure.NORMAL)
+
+		// 前两次分别返回503和429，第三次成功
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch calls.Add(1) {
+			case 1:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case 2:
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			default:
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`[]`))
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		policy := client.RetryPolicy{
+			Count:          3,
+			Statuses:       []int{429, 503},
+			NetworkErrors:  true,
+			WaitTime:       10 * time.Millisecond,
+			MaxWaitTime:    40 * time.Millisecond,
+			Jitter:         0.5,
+			RetryAfter:     true,
+			ExcludeMethods: []string{"POST"},
+		}
+		reporter, ok := t.(*utils.AllureReporter)
+		t.Require().True(ok, "runTest 应该传入 AllureReporter")
+		apiClient := client.New(nil,
+			client.WithBaseURL(server.URL),
+			client.WithRetryPolicy(policy),
+			client.WithHook(reporter.Hook),
+		)
+
+		t.WithNewStep("计算退避时间", func(sCtx provider.StepCtx) {
+			for attempt := 1; attempt <= 4; attempt++ {
+				backoff := policy.WaitTime << (attempt - 1)
+				if backoff > policy.MaxWaitTime {
+					backoff = policy.MaxWaitTime
+				}
+				delay := policy.Delay(attempt, nil)
+				t.Assert().LessOrEqual(delay, backoff, "第%d次等待不应该超过退避时间", attempt)
+				t.Assert().GreaterOrEqual(delay, backoff/2, "抖动不应该超过 Jitter 比例")
+			}
+
+			header := http.Header{}
+			header.Set("Retry-After", "0")
+			t.Assert().Equal(time.Duration(0), policy.Delay(1, header), "应该按 Retry-After 等待")
+			header.Set("Retry-After", "120")
+			t.Assert().Equal(policy.MaxWaitTime, policy.Delay(1, header), "Retry-After 不应该超过 MaxWaitTime")
+			header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+			t.Assert().Equal(time.Duration(0), policy.Delay(1, header), "过去的 HTTP 日期应该立即重试")
+
+			t.Assert().False(policy.ShouldRetry("POST", 503, nil), "排除的方法不应该重试")
+			t.Assert().False(policy.ShouldRetry("GET", 404, nil), "不在列表中的状态码不应该重试")
+		})
+
+		t.WithNewStep("GET 请求在503和429后重试成功", func(sCtx provider.StepCtx) {
+			_, resp, err := apiClient.GetAllProducts()
+			t.Require().NoError(err, "重试后请求应该成功")
+
+			attempts := client.Attempts(resp)
+			t.Require().Len(attempts, 3, "应该尝试3次")
+			t.Assert().Equal([]int{503, 429, 200}, []int{attempts[0].StatusCode, attempts[1].StatusCode, attempts[2].StatusCode})
+			for i, attempt := range attempts {
+				t.Assert().Equal(i+1, attempt.Number)
+				t.Assert().Greater(attempt.Duration, time.Duration(0), "每次尝试都应该记录耗时")
+			}
+			t.Assert().Greater(attempts[0].Wait, time.Duration(0), "503后应该按退避时间等待")
+			t.Assert().LessOrEqual(attempts[0].Wait, policy.WaitTime)
+			t.Assert().Equal(time.Duration(0), attempts[1].Wait, "429后应该按 Retry-After 等待")
+			t.Assert().Equal(time.Duration(0), attempts[2].Wait, "最后一次尝试没有等待")
+
+			var names []string
+			for _, a := range sCtx.CurrentStep().Attachments {
+				names = append(names, a.Name)
+			}
+			t.Assert().Contains(names, "GET /products 请求")
+			t.Assert().Contains(names, "GET /products #2 响应", "重试的尝试应该单独写入附件")
+			t.Assert().Contains(names, "GET /products #3 响应")
+		})
+
+		t.WithNewStep("POST 请求不重试", func(sCtx provider.StepCtx) {
+			calls.Store(0)
+			_, resp, err := apiClient.CreateProduct(models.CreateProductRequest{Title: "retry", Price: 1, Category: "test"})
+			t.Require().Error(err, "503应该返回错误")
+			t.Assert().Len(client.Attempts(resp), 1, "非幂等请求只应该发送一次")
+			t.Assert().Equal(int32(1), calls.Load())
+		})
+	})
+}
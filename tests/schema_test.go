@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/models"
+	"go-testify-allure-api-test/schema"
+	"go-testify-allure-api-test/utils"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// invalidProducts 违反 Product Schema 的商品列表
+const invalidProducts = `[
+	{"id":0,"title":"","price":"9.99","description":"d","category":"c","image":"img.jpg","rating":{"rate":5.5,"count":-1}},
+	{"id":2,"title":"B","price":1,"description":"d","category":"c","image":"https://example.com/b.jpg"}
+]`
+
+// recordingT 记录断言失败信息的 utils.TestingT
+type recordingT struct {
+	errors []string
+}
+
+// Errorf 记录失败信息
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// FailNow 不中止当前用例，只记录失败
+func (r *recordingT) FailNow() {}
+
+// Logf 忽略日志
+func (r *recordingT) Logf(string, ...interface{}) {}
+
+// TestResponseSchemas 测试响应符合由模型生成的 JSON Schema
+func TestResponseSchemas(t *testing.T) {
+	runTest(t, "Response JSON Schemas", func(t provider.T) {
+		t.Tags("contract", "schema")
+		t.Description("由 models 的 jsonschema 标签生成 JSON Schema，校验各接口的响应，并验证违规按 JSON Pointer 报告")
+		t.Severity(allure.CRITICAL)
+
+		apiClient := newTestClient(t)
+		helper := utils.NewTestHelper(t)
+
+		t.WithNewStep("生成 Product 的 Schema", func(sCtx provider.StepCtx) {
+			s := schema.For(models.Product{})
+			sCtx.WithNewAttachment("Product.schema.json", allure.JSON, []byte(s.String()))
+
+			t.Assert().Equal(schema.Draft, s.Schema)
+			t.Assert().Equal("object", s.Type)
+			t.Assert().ElementsMatch([]string{"id", "title", "price", "description", "category", "image", "rating"}, s.Required)
+			t.Assert().Equal("uri", s.Properties["image"].Format)
+			rate := s.Properties["rating"].Properties["rate"]
+			t.Require().NotNil(rate.Maximum)
+			t.Assert().Equal(5.0, *rate.Maximum)
+			t.Assert().Equal("date-time", schema.For(models.Cart{}).Properties["date"].Format)
+			t.Assert().Len(schema.Models(), 9)
+		})
+
+		responses := []struct {
+			name string
+			s    *schema.Schema
+			call func() (*resty.Response, error)
+		}{
+			{"GET /products", schema.For([]models.Product{}), func() (*resty.Response, error) {
+				_, resp, err := apiClient.GetAllProducts()
+				return resp, err
+			}},
+			{"GET /users", schema.For([]models.User{}), func() (*resty.Response, error) {
+				_, resp, err := apiClient.GetAllUsers()
+				return resp, err
+			}},
+			{"GET /carts", schema.For([]models.Cart{}), func() (*resty.Response, error) {
+				_, resp, err := apiClient.GetAllCarts()
+				return resp, err
+			}},
+			{"POST /auth/login", schema.For(models.LoginResponse{}), func() (*resty.Response, error) {
+				_, resp, err := apiClient.Login(models.LoginRequest{Username: "mor_2314", Password: "83r5^_"})
+				return resp, err
+			}},
+		}
+		for _, r := range responses {
+			r := r
+			t.WithNewStep(r.name+" 符合 Schema", func(sCtx provider.StepCtx) {
+				resp, err := r.call()
+				t.Require().NoError(err, "请求不应该返回错误")
+				helper.AssertSchema(resp, r.s, r.name)
+			})
+		}
+
+		t.WithNewStep("违规按 JSON Pointer 报告", func(sCtx provider.StepCtx) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(invalidProducts))
+			}))
+			t.Cleanup(server.Close)
+
+			_, resp, err := client.New(nil, client.WithBaseURL(server.URL), client.WithRetry(0, 0, 0)).GetAllProducts()
+			t.Require().Error(err, "price 为字符串，解码应该失败")
+			t.Require().NotNil(resp)
+
+			violations, err := schema.ValidateResponse(resp, schema.For([]models.Product{}))
+			t.Require().NoError(err)
+			got := make(map[string]string)
+			for _, v := range violations {
+				got[v.Pointer] = v.Keyword
+			}
+			t.Assert().Equal(map[string]string{
+				"/0/id":           "minimum",
+				"/0/title":        "minLength",
+				"/0/price":        "type",
+				"/0/image":        "format",
+				"/0/rating/rate":  "maximum",
+				"/0/rating/count": "minimum",
+				"/1/rating":       "required",
+			}, got)
+
+			recorder := &recordingT{}
+			t.Assert().False(utils.NewTestHelper(recorder).AssertSchema(resp, schema.For([]models.Product{}), "商品列表"))
+			t.Require().Len(recorder.errors, 1)
+			t.Assert().Contains(recorder.errors[0], "共 7 处违规")
+			t.Assert().Contains(recorder.errors[0], "/0/rating/rate: 5.5 大于最大值 5")
+		})
+
+		t.WithNewStep("$defs 引用和非法 JSON", func(sCtx provider.StepCtx) {
+			s := &schema.Schema{
+				Type:  "array",
+				Items: &schema.Schema{Ref: "#/$defs/CartProduct"},
+				Defs:  map[string]*schema.Schema{"CartProduct": schema.For(models.CartProduct{})},
+			}
+			violations, err := s.Validate([]byte(`[{"productId":1,"quantity":1.5},{"productId":1}]`))
+			t.Require().NoError(err)
+			t.Require().Len(violations, 2)
+			t.Assert().Equal("/0/quantity", violations[0].Pointer)
+			t.Assert().Equal("type", violations[0].Keyword)
+			t.Assert().Equal("/1/quantity", violations[1].Pointer)
+
+			_, err = s.Validate([]byte(`[{`))
+			t.Assert().Error(err, "非法 JSON 应该返回错误")
+		})
+	})
+}
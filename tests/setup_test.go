@@ -0,0 +1,271 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/config"
+	"go-testify-allure-api-test/fakestore"
+	"go-testify-allure-api-test/har"
+	"go-testify-allure-api-test/openapi"
+	"go-testify-allure-api-test/utils"
+	"go-testify-allure-api-test/vcr"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/runner"
+)
+
+// cleanupTimeout 测试结束后撤销资源的总超时时间
+const cleanupTimeout = 30 * time.Second
+
+// testState 单个用例在 runTest 与 newTestClient 之间共享的状态
+type testState struct {
+	cleanup  *client.Cleanup   // test.cleanup 为 false 时为nil
+	har      *har.Recorder     // har.scope 为 test 时的记录器，否则为nil
+	vcr      *vcr.Transport    // vcr.mode 为 record 或 replay 时的磁带，否则为nil
+	contract *openapi.Checker  // openapi.enabled 为 true 时校验用例的请求和响应，否则为nil
+	server   *fakestore.Server // 离线模式下用例独占的替身服务，否则为nil
+
+	unavailable atomic.Bool // 是否有请求因熔断被拒绝
+}
+
+// states 正在运行的用例的状态，键为用例名称
+var states sync.Map
+
+// runTest 所有用例的公共入口：test.parallel 为 true 时先把用例标记为并行，再交给 Allure 运行。
+// 必须在顶层 *testing.T 上调用 Parallel，否则用例只会与同一 runner.Run 下的子测试并行。
+// test.cleanup 为 true 时，用例通过 newTestClient 创建或修改的资源会在用例结束时撤销。
+// 用例拿到的 t 是 utils.AllureReporter，newTestClient 发出的请求会自动作为附件写入当前步骤。
+// har.scope 为 test 时，用例结束后把该用例的全部请求（包括清理请求）写入单独的 HAR 文件。
+// vcr.mode 为 record 时，用例结束后把请求保存为该用例的磁带；为 replay 时只从磁带回放，
+// 磁带中没有匹配的请求会使用例失败。
+// openapi.enabled 为 true 时，用例的请求和响应按契约校验，违规作为附件写入用例，
+// openapi.mode 为 fail 时违规会使用例失败（服务端以4xx拒绝的无效请求除外）。
+// 用例失败且有请求因熔断被拒绝时，说明目标服务不可用而不是被测功能有误，结果标记为 broken。
+// 预检失败且 preflight.on_failure 为 skip 时，用例直接跳过
+func runTest(t *testing.T, name string, body func(provider.T)) {
+	cfg := config.GetConfig()
+	if cfg.Test.Parallel {
+		t.Parallel()
+	}
+	runner.Run(t, name, func(t provider.T) {
+		if preflightFailure != "" {
+			t.Skip(preflightFailure)
+		}
+		state := &testState{}
+		if cfg.Test.Cleanup {
+			state.cleanup = client.NewCleanup()
+		}
+		if cfg.HAR.Enabled && cfg.HAR.Scope == harScopeTest {
+			state.har = har.NewRecorder()
+		}
+		var cassette *vcr.Cassette
+		if cfg.VCR.Mode != string(vcr.ModePassthrough) {
+			cassette, state.vcr = newCassette(t, cfg)
+		}
+		if contract != nil {
+			state.contract = openapi.NewChecker(contract)
+		}
+		if offline {
+			state.server = fakestore.NewServer()
+		}
+		states.Store(t.RealT().Name(), state)
+
+		// 必须在用例函数返回前执行，t.Cleanup 的回调晚于 Allure 写入结果
+		defer func() {
+			states.Delete(t.RealT().Name())
+			if state.cleanup != nil {
+				runCleanup(t, state.cleanup)
+			}
+			if state.har != nil && state.har.Len() > 0 {
+				path := utils.HARPath(t.RealT().Name())
+				if err := state.har.WriteFile(path, t.RealT().Name()); err != nil {
+					t.Logf("Warning: Could not write HAR file %s: %v", path, err)
+				}
+			}
+			if state.vcr != nil {
+				finishCassette(t, cfg, cassette, state.vcr)
+			}
+			if state.contract != nil {
+				finishContract(t, cfg, state.contract)
+			}
+			markUnavailable(t, state)
+			// 清理请求也发往替身服务，最后关闭
+			if state.server != nil {
+				state.server.Close()
+			}
+		}()
+		body(utils.NewAllureReporter(t))
+	})
+	if preflightFailure != "" {
+		// Allure 的子测试已跳过，顶层用例同样标记为跳过，go test 的输出中不会显示为 PASS
+		t.Skip(preflightFailure)
+	}
+}
+
+// markUnavailable 用例失败且有请求因熔断被拒绝时，把结果标记为 broken
+func markUnavailable(t provider.T, state *testState) {
+	if state.unavailable.Load() && t.Failed() {
+		t.Logf("目标服务不可用，用例标记为 broken")
+		t.Broken()
+	}
+}
+
+// newTestClient 为当前用例创建独立的客户端，请求日志带有用例名称，请求和响应写入 Allure 附件。
+// 离线模式下每个用例使用 runTest 启动的替身服务，同一用例创建的客户端共享该服务，
+// 用例之间的增删改互不影响，并行与串行运行结果一致。extra 追加在默认选项之后，可以覆盖默认值
+func newTestClient(t provider.T, extra ...client.Option) *client.APIClient {
+	opts := []client.Option{client.WithTestName(t.Name())}
+	if reporter, ok := t.(*utils.AllureReporter); ok {
+		opts = append(opts, client.WithHook(reporter.Hook))
+	}
+	if runHAR != nil {
+		opts = append(opts, client.WithHook(runHAR.Hook))
+	}
+	if value, ok := states.Load(t.RealT().Name()); ok {
+		state := value.(*testState)
+		if state.server != nil {
+			opts = append(opts, client.WithBaseURL(state.server.URL()))
+		}
+		if state.cleanup != nil {
+			opts = append(opts, client.WithCleanup(state.cleanup))
+		}
+		if state.har != nil {
+			opts = append(opts, client.WithHook(state.har.Hook))
+		}
+		opts = append(opts, client.WithHook(func(_ context.Context, e client.Exchange) {
+			if errors.Is(e.Err, client.ErrTargetUnavailable) {
+				state.unavailable.Store(true)
+			}
+		}))
+		var transport http.RoundTripper
+		if state.vcr != nil {
+			transport = state.vcr
+			if cfg := config.GetConfig(); cfg.VCR.Mode == string(vcr.ModeReplay) {
+				// 回放失败不是网络问题，重试只会重复消耗磁带中的交互
+				opts = append(opts, client.WithRetry(0, 0, 0))
+			}
+		}
+		if state.contract != nil {
+			// 校验放在磁带之外，回放的响应同样按契约校验
+			transport = state.contract.Transport(transport)
+		}
+		if transport != nil {
+			opts = append(opts, client.WithTransport(transport))
+		}
+	}
+	return client.New(nil, append(opts, extra...)...)
+}
+
+// fakeStoreStep 只在离线模式下执行的步骤。真实的 fakestoreapi.com 不保存创建、修改和删除，
+// 写入后再读取的断言只对替身服务成立；请求真实服务时步骤标记为 skipped，用例其余步骤照常执行
+func fakeStoreStep(t provider.T, name string, step func(sCtx provider.StepCtx)) {
+	if offline {
+		t.WithNewStep(name, step)
+		return
+	}
+	t.WithNewStep(name, func(sCtx provider.StepCtx) {
+		sCtx.CurrentStep().Skipped()
+		sCtx.Logf("跳过: 真实的 fakestoreapi.com 不保存写操作，仅在 %s 模式下验证", fakeStoreEnv)
+	})
+}
+
+// newCassette 按 vcr 配置创建用例的磁带：录制模式创建空磁带，回放模式读取已有的磁带。
+// 不发送请求的用例录制时不生成磁带，回放时按空磁带处理
+func newCassette(t provider.T, cfg *config.Config) (*vcr.Cassette, *vcr.Transport) {
+	matcher, err := vcr.NewMatcher(cfg.VCR.Match)
+	t.Require().NoError(err, "vcr.match 配置无效")
+
+	cassette := &vcr.Cassette{Name: t.RealT().Name()}
+	if cfg.VCR.Mode == string(vcr.ModeReplay) {
+		path := vcr.Path(cfg.VCR.Dir, utils.SafeFileName(t.RealT().Name()), cfg.VCR.Format)
+		if loaded, err := vcr.Load(path); err == nil {
+			cassette = loaded
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			t.Require().NoError(err, "读取磁带失败")
+		}
+	}
+	return cassette, vcr.NewTransport(vcr.Mode(cfg.VCR.Mode), cassette, matcher, nil)
+}
+
+// finishCassette 录制模式下保存磁带；回放模式下把磁带中没有匹配的请求报告为用例错误
+func finishCassette(t provider.T, cfg *config.Config, cassette *vcr.Cassette, transport *vcr.Transport) {
+	if cfg.VCR.Mode == string(vcr.ModeRecord) {
+		if cassette.Len() == 0 {
+			return
+		}
+		path := vcr.Path(cfg.VCR.Dir, utils.SafeFileName(t.RealT().Name()), cfg.VCR.Format)
+		if err := cassette.Save(path); err != nil {
+			t.Errorf("保存磁带 %s 失败: %v", path, err)
+		}
+		return
+	}
+	for _, req := range transport.Unmatched() {
+		t.Errorf("磁带中没有匹配的请求: %s", req)
+	}
+}
+
+// finishContract 把用例的契约校验结果并入整次运行的汇总，违规作为附件写入用例；
+// openapi.mode 为 fail 时把违规报告为用例错误
+func finishContract(t provider.T, cfg *config.Config, checker *openapi.Checker) {
+	runContract.Merge(checker)
+	violations := checker.Violations()
+	if len(violations) == 0 {
+		return
+	}
+	lines := make([]string, len(violations))
+	for i, v := range violations {
+		lines[i] = v.String()
+	}
+	t.WithNewAttachment("OpenAPI 违规", allure.Text, []byte(strings.Join(lines, "\n")))
+
+	failures := checker.Failures()
+	if len(failures) == 0 || cfg.OpenAPI.Mode != "fail" {
+		return
+	}
+	lines = lines[:0]
+	for _, v := range failures {
+		lines = append(lines, v.String())
+	}
+	t.Errorf("响应或请求不符合 OpenAPI 契约，共 %d 处违规:\n  %s", len(failures), strings.Join(lines, "\n  "))
+}
+
+// runCleanup 撤销登记的资源，并在 Allure 中记录为 teardown 步骤。
+// 清理失败的步骤标记为 broken，但不影响用例本身的结果
+func runCleanup(t provider.T, registry *client.Cleanup) {
+	if registry.Len() == 0 {
+		return
+	}
+	t.WithTestTeardown(func(t provider.T) {
+		t.WithNewStep("清理测试数据", func(sCtx provider.StepCtx) {
+			ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+			defer cancel()
+
+			for _, result := range registry.Run(ctx) {
+				result := result
+				sCtx.WithNewStep(result.Action, func(stepCtx provider.StepCtx) {
+					stepCtx.WithNewParameters("status", string(result.Status))
+					switch result.Status {
+					case client.CleanupFailed:
+						stepCtx.CurrentStep().Broken()
+						stepCtx.WithNewAttachment("error", allure.Text, []byte(result.Err.Error()))
+						sCtx.CurrentStep().Broken()
+						stepCtx.Logf("清理失败: %s - %v", result.Action, result.Err)
+					case client.CleanupSkipped:
+						stepCtx.CurrentStep().Skipped()
+						stepCtx.Logf("跳过清理: %s - %v", result.Action, result.Err)
+					}
+				})
+			}
+		})
+	})
+}
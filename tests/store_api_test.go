@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/fakestore"
+	"go-testify-allure-api-test/models"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// storeCall 通过 StoreAPI 完成一次调用，返回解码后的结果和错误
+type storeCall struct {
+	name string
+	call func(ctx context.Context, api client.StoreAPI) (interface{}, error)
+}
+
+// storeCalls 依次执行的调用，覆盖每类资源的读写和错误响应；写操作会影响之后的结果
+var storeCalls = []storeCall{
+	{"GetProductsByLimit", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.GetProductsByLimit(ctx, 3)
+	}},
+	{"GetProductsBySort desc", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.GetProductsBySort(ctx, "desc")
+	}},
+	{"GetProductsBySort invalid", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.GetProductsBySort(ctx, "sideways")
+	}},
+	{"GetProductByID missing", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.GetProductByID(ctx, 9999)
+	}},
+	{"CreateProduct", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.CreateProduct(ctx, models.CreateProductRequest{Title: "Contract", Price: 9.5, Category: "electronics"})
+	}},
+	{"PatchProduct", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.PatchProduct(ctx, 1, models.UpdateProductRequest{Price: 1.25})
+	}},
+	{"UpdateProduct", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.UpdateProduct(ctx, 2, models.UpdateProductRequest{Title: "Replaced"})
+	}},
+	{"DeleteProduct", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.DeleteProduct(ctx, 3)
+	}},
+	{"GetAllCategories", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.GetAllCategories(ctx)
+	}},
+	{"GetProductsByCategory", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.GetProductsByCategory(ctx, "electronics")
+	}},
+	{"GetCartsByDateRange", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.GetCartsByDateRange(ctx, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC))
+	}},
+	{"GetCartsByUserID", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.GetCartsByUserID(ctx, 1)
+	}},
+	{"CreateCart without user", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.CreateCart(ctx, models.CreateCartRequest{})
+	}},
+	{"PatchCart", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.PatchCart(ctx, 1, models.UpdateCartRequest{Products: []models.CartProduct{{ProductID: 2, Quantity: 7}}})
+	}},
+	{"GetUsersByLimit", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.GetUsersByLimit(ctx, 2)
+	}},
+	{"AddUser duplicate", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.AddUser(ctx, models.CreateUserRequest{Email: "dup@example.com", Username: "mor_2314", Password: "secret"})
+	}},
+	{"PatchUser", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.PatchUser(ctx, 1, models.UpdateUserRequest{Phone: "1-555-0100"})
+	}},
+	{"DeleteUser missing", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.DeleteUser(ctx, 9999)
+	}},
+	{"Login wrong password", func(ctx context.Context, api client.StoreAPI) (interface{}, error) {
+		return api.Login(ctx, models.LoginRequest{Username: "mor_2314", Password: "wrong"})
+	}},
+}
+
+// TestStoreAPIConformance 测试内存实现与 HTTP 客户端的行为一致
+func TestStoreAPIConformance(t *testing.T) {
+	runTest(t, "StoreAPI conformance", func(t provider.T) {
+		t.Tags("client", "store-api")
+		t.Description("对替身服务上的 APIClient 和内存中的 fakestore.Client 执行同样的调用，比较结果、状态码和错误")
+		t.Severity(allure.NORMAL)
+
+		server := fakestore.NewServer()
+		t.Cleanup(server.Close)
+		var (
+			httpAPI = client.New(nil,
+				client.WithBaseURL(server.URL()),
+				client.WithRetry(0, 0, 0),
+				client.WithBreaker(nil),
+				client.WithFaults(nil),
+			).StoreAPI()
+			fakeAPI client.StoreAPI = fakestore.NewClient(nil)
+			ctx                     = context.Background()
+		)
+
+		for _, c := range storeCalls {
+			c := c
+			t.WithNewStep(c.name, func(sCtx provider.StepCtx) {
+				want, wantErr := c.call(ctx, httpAPI)
+				got, gotErr := c.call(ctx, fakeAPI)
+
+				t.Assert().Equal(want, got, "结果应该一致")
+				t.Assert().Equal(wantErr == nil, gotErr == nil, "是否返回错误应该一致: %v / %v", wantErr, gotErr)
+
+				var wantAPIErr, gotAPIErr *client.APIError
+				if errors.As(wantErr, &wantAPIErr) {
+					t.Require().True(errors.As(gotErr, &gotAPIErr), "应该返回 APIError")
+					t.Assert().Equal(wantAPIErr.StatusCode, gotAPIErr.StatusCode, "状态码应该一致")
+					t.Assert().Equal(wantAPIErr.Method, gotAPIErr.Method, "请求方法应该一致")
+					t.Assert().Equal(wantAPIErr.Response, gotAPIErr.Response, "错误响应应该一致")
+					t.Assert().Equal(wantAPIErr.Body, gotAPIErr.Body, "原始响应体应该一致")
+					t.Assert().NotEmpty(gotAPIErr.RequestID, "错误中应该记录请求ID")
+					t.Assert().Contains(gotAPIErr.Curl, "curl", "错误中应该带有复现请求的 curl 命令")
+				}
+			})
+		}
+
+		t.WithNewStep("登录成功并设置令牌", func(sCtx provider.StepCtx) {
+			login, err := fakeAPI.Login(ctx, models.LoginRequest{Username: "mor_2314", Password: "83r5^_"})
+			t.Require().NoError(err)
+			t.Require().NotEmpty(login.Token)
+
+			// 通过错误中的 curl 命令确认请求带有 Authorization 头，令牌的值已隐藏
+			var apiErr *client.APIError
+			_, err = fakeAPI.GetUserByID(ctx, 9999)
+			t.Require().True(errors.As(err, &apiErr))
+			t.Assert().NotContains(apiErr.Curl, "Authorization", "设置令牌前不应该带有 Authorization 头")
+
+			fakeAPI.SetAuthToken(login.Token)
+			_, err = fakeAPI.GetUserByID(ctx, 9999)
+			t.Require().True(errors.As(err, &apiErr))
+			t.Assert().Contains(apiErr.Curl, "Authorization", "设置令牌后应该带有 Authorization 头")
+			t.Assert().NotContains(apiErr.Curl, login.Token, "curl 命令中不应该出现令牌")
+		})
+
+		t.WithNewStep("已取消的 context", func(sCtx provider.StepCtx) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := fakeAPI.GetAllProducts(ctx)
+			t.Assert().True(errors.Is(err, client.ErrRequestCanceled), "应该返回 ErrRequestCanceled: %v", err)
+			t.Assert().True(errors.Is(err, context.Canceled))
+		})
+	})
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-testify-allure-api-test/client"
+	"go-testify-allure-api-test/fakestore"
+	"go-testify-allure-api-test/models"
+	"go-testify-allure-api-test/vcr"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// TestVCRRecordAndReplay 测试录制和回放 HTTP 交互
+func TestVCRRecordAndReplay(t *testing.T) {
+	runTest(t, "VCR record and replay", func(t provider.T) {
+		t.Tags("client", "vcr")
+		t.Description("验证录制的磁带可以在服务不可用时回放，未匹配的请求直接失败，YAML 和 JSON 格式均可读写")
+		t.Severity(allure.NORMAL)
+
+		dir, err := os.MkdirTemp("", "apitest-vcr")
+		t.Require().NoError(err, "创建临时目录不应该返回错误")
+		t.Cleanup(func() { os.RemoveAll(dir) })
+
+		matcher, err := vcr.NewMatcher([]string{vcr.MatchMethod, vcr.MatchPath, vcr.MatchQuery, vcr.MatchBody})
+		t.Require().NoError(err)
+
+		login := models.LoginRequest{Username: "mor_2314", Password: "83r5^_"}
+		var recorded []models.Product
+
+		for _, format := range []string{vcr.FormatYAML, vcr.FormatJSON} {
+			format := format
+			path := vcr.Path(dir, "cassette", format)
+
+			t.WithNewStep("录制磁带 ("+format+")", func(sCtx provider.StepCtx) {
+				server := fakestore.NewServer()
+				defer server.Close()
+
+				cassette := &vcr.Cassette{Name: t.Name()}
+				transport := vcr.NewTransport(vcr.ModeRecord, cassette, matcher, nil)
+				apiClient := client.New(nil, client.WithBaseURL(server.URL()), client.WithTransport(transport))
+
+				recorded, _, err = apiClient.GetProductsByLimit(3)
+				t.Require().NoError(err, "录制时请求不应该返回错误")
+				_, _, err = apiClient.Login(login)
+				t.Require().NoError(err, "录制时登录不应该返回错误")
+
+				t.Require().Equal(2, cassette.Len(), "应该录制两次交互")
+				t.Require().NoError(cassette.Save(path), "保存磁带不应该返回错误")
+
+				content, err := os.ReadFile(path)
+				t.Require().NoError(err)
+				sCtx.WithNewAttachment(filepath.Base(path), allure.Text, content)
+				t.Assert().NotContains(string(content), login.Password, "磁带中不应该出现密码")
+			})
+
+			t.WithNewStep("在服务关闭后回放磁带 ("+format+")", func(sCtx provider.StepCtx) {
+				cassette, err := vcr.Load(path)
+				t.Require().NoError(err, "读取磁带不应该返回错误")
+
+				transport := vcr.NewTransport(vcr.ModeReplay, cassette, matcher, nil)
+				apiClient := client.New(nil,
+					client.WithBaseURL("http://127.0.0.1:1"),
+					client.WithTransport(transport),
+					client.WithRetry(0, 0, 0),
+				)
+
+				products, resp, err := apiClient.GetProductsByLimit(3)
+				t.Require().NoError(err, "回放时请求不应该返回错误")
+				t.Assert().Equal(200, resp.StatusCode())
+				t.Assert().Equal(recorded, products, "回放的响应应该与录制时一致")
+
+				_, _, err = apiClient.Login(login)
+				t.Assert().NoError(err, "请求体相同的登录请求应该匹配")
+
+				matched := matcher.Match(
+					vcr.Request{Method: "POST", URL: "/auth/login", Body: `{"username":"mor_2314","password":"******"}`},
+					vcr.Request{Method: "POST", URL: "/auth/login", Body: `{"password":"83r5^_","username":"mor_2314"}`},
+				)
+				t.Assert().True(matched, "未隐藏密码的旧磁带也应该匹配")
+
+				_, _, err = apiClient.Login(models.LoginRequest{Username: "other", Password: "x"})
+				t.Assert().True(errors.Is(err, vcr.ErrNoInteraction), "请求体不同的请求不应该匹配")
+
+				_, _, err = apiClient.GetProductsByLimit(3)
+				t.Assert().True(errors.Is(err, vcr.ErrNoInteraction), "每次交互只能回放一次")
+				t.Assert().Equal([]string{"POST /auth/login", "GET /products?limit=3"}, transport.Unmatched(), "应该记录所有未匹配的请求")
+			})
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"go-testify-allure-api-test/config"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+)
+
+const (
+	allureOutputPathEnv   = "ALLURE_OUTPUT_PATH"
+	allureOutputFolderEnv = "ALLURE_OUTPUT_FOLDER"
+	allureLaunchTagsEnv   = "ALLURE_LAUNCH_TAGS"
+)
+
+// ConfigureAllure 让 allure-go 把结果写入 allure.results_dir，并为本次运行的所有用例打上环境标签。
+// 已显式设置的 ALLURE_OUTPUT_FOLDER 优先
+func ConfigureAllure(cfg *config.Config) {
+	if os.Getenv(allureOutputFolderEnv) == "" && cfg.Allure.ResultsDir != "" {
+		os.Setenv(allureOutputFolderEnv, cfg.Allure.ResultsDir)
+	}
+
+	tag := "env:" + cfg.ProfileName()
+	if tags := os.Getenv(allureLaunchTagsEnv); tags != "" {
+		tag = tags + "," + tag
+	}
+	os.Setenv(allureLaunchTagsEnv, tag)
+}
+
+// AllureResultsPath 返回 allure-go 写入结果的目录，规则与 allure-go 一致
+func AllureResultsPath() string {
+	folder := os.Getenv(allureOutputFolderEnv)
+	if folder == "" {
+		folder = "allure-results"
+	}
+	if path := os.Getenv(allureOutputPathEnv); path != "" {
+		return filepath.Join(path, folder)
+	}
+	return folder
+}
+
+// HARPath 返回用例 HAR 文件在结果目录中的路径
+func HARPath(testName string) string {
+	return filepath.Join(AllureResultsPath(), SafeFileName(testName)+".har")
+}
+
+// SafeFileName 把用例名称转换为文件名，/ 和空格等字符替换为 _
+func SafeFileName(testName string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, testName)
+}
+
+// WriteAllureEnvironment 将环境信息写入结果目录下的 environment.properties，
+// 显示在 Allure 报告的 Environment 面板中
+func WriteAllureEnvironment(cfg *config.Config) error {
+	props := map[string]string{
+		"environment":     cfg.ProfileName(),
+		"api.base_url":    cfg.API.BaseURL,
+		"api.timeout":     fmt.Sprintf("%ds", cfg.API.Timeout),
+		"api.retry_count": fmt.Sprintf("%d", cfg.API.RetryCount),
+	}
+
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, props[key])
+	}
+
+	dir := AllureResultsPath()
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "environment.properties"), []byte(b.String()), 0644)
+}
+
+// WriteBrokenResult 在结果目录中写入一个状态为 broken 的独立用例结果，
+// 用于在用例开始前就失败的情况（如预检失败），report 作为文本附件
+func WriteBrokenResult(name, message, report string) error {
+	return WriteResult(name, "Pre-flight", allure.Broken, message, report)
+}
+
+// WriteResult 在结果目录中写入一个不属于任何用例的独立结果，归入 suite 套件，
+// 用于预检、整次运行的契约汇总等，report 非空时作为文本附件
+func WriteResult(name, suite string, status allure.Status, message, report string) error {
+	result := allure.NewResult(name, name).WithLaunchTags()
+	result.AddLabel(allure.SuiteLabel(suite))
+	result.Status = status
+	result.StatusDetails = allure.StatusDetail{Message: message}
+	if report != "" {
+		result.Attachments = append(result.Attachments, allure.NewAttachment(name, allure.Text, []byte(report)))
+	}
+	return result.Done()
+}
@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"go-testify-allure-api-test/client"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// AllureReporter 包装 provider.T，跟踪用例当前所在的 Allure 步骤。
+// 把 Hook 注册到 APIClient 后，每次请求的请求行、请求头、请求体以及响应状态、响应头、响应体和耗时
+// 以及复现请求的 curl 命令都会作为附件写入当前步骤，严格解码模式下发现的模型差异另作为 JSON 附件；请求发生在步骤之外时单独生成一个步骤
+type AllureReporter struct {
+	provider.T
+
+	mu    sync.Mutex
+	steps []provider.StepCtx
+}
+
+// NewAllureReporter 创建包装 t 的 AllureReporter，用例中应使用返回值代替 t
+func NewAllureReporter(t provider.T) *AllureReporter {
+	return &AllureReporter{T: t}
+}
+
+// WithNewStep 与 provider.T.WithNewStep 相同，同时记录当前步骤
+func (r *AllureReporter) WithNewStep(stepName string, step func(sCtx provider.StepCtx), params ...*allure.Parameter) {
+	r.T.WithNewStep(stepName, r.track(step), params...)
+}
+
+// Hook 把一次请求写入当前步骤，可通过 client.WithHook 注册
+func (r *AllureReporter) Hook(_ context.Context, e client.Exchange) {
+	name := exchangeName(e)
+	attachments := []*allure.Attachment{
+		allure.NewAttachment(name+" 请求", allure.Text, formatRequest(e)),
+		allure.NewAttachment(name+" 响应", allure.Text, formatResponse(e)),
+		allure.NewAttachment(name+" cURL", allure.Text, formatCurl(e)),
+	}
+	if e.Drift != nil {
+		report, _ := json.MarshalIndent(e.Drift, "", "  ")
+		attachments = append(attachments, allure.NewAttachment(name+" 模型差异", allure.JSON, report))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n := len(r.steps); n > 0 {
+		r.steps[n-1].WithAttachments(attachments...)
+		return
+	}
+
+	step := allure.NewSimpleStep(name + " → " + exchangeStatus(e))
+	step.WithAttachments(attachments...)
+	if e.Err != nil {
+		step.Broken()
+	}
+	r.T.Step(step)
+}
+
+// track 包装步骤函数，在执行期间把该步骤记为当前步骤
+func (r *AllureReporter) track(step func(sCtx provider.StepCtx)) func(sCtx provider.StepCtx) {
+	return func(sCtx provider.StepCtx) {
+		r.mu.Lock()
+		r.steps = append(r.steps, sCtx)
+		r.mu.Unlock()
+		defer func() {
+			r.mu.Lock()
+			r.steps = r.steps[:len(r.steps)-1]
+			r.mu.Unlock()
+		}()
+		step(&reportedStep{StepCtx: sCtx, r: r})
+	}
+}
+
+// reportedStep 让嵌套步骤同样被跟踪
+type reportedStep struct {
+	provider.StepCtx
+	r *AllureReporter
+}
+
+// WithNewStep 与 provider.StepCtx.WithNewStep 相同，同时记录当前步骤
+func (s *reportedStep) WithNewStep(stepName string, step func(sCtx provider.StepCtx), params ...*allure.Parameter) {
+	s.StepCtx.WithNewStep(stepName, s.r.track(step), params...)
+}
+
+// exchangeName 返回 "GET /products/1" 形式的请求名称，重试的尝试带有尝试次数，如 "GET /products/1 #2"
+func exchangeName(e client.Exchange) string {
+	path := e.Request.URL
+	if e.Request.RawRequest != nil {
+		path = e.Request.RawRequest.URL.Path
+	}
+	name := e.Request.Method + " " + path
+	if e.Request.Attempt > 1 {
+		name += fmt.Sprintf(" #%d", e.Request.Attempt)
+	}
+	return name
+}
+
+// exchangeStatus 返回响应状态或错误描述
+func exchangeStatus(e client.Exchange) string {
+	if e.Response == nil {
+		return "error"
+	}
+	return fmt.Sprintf("%d", e.Response.StatusCode())
+}
+
+// formatRequest 格式化请求行、请求头和请求体，敏感请求头和请求体字段的值已隐藏
+func formatRequest(e client.Exchange) []byte {
+	var b bytes.Buffer
+	url := e.Request.URL
+	header := e.Request.Header
+	if raw := e.Request.RawRequest; raw != nil {
+		url = raw.URL.String()
+		header = raw.Header
+	}
+	fmt.Fprintf(&b, "%s %s\n", e.Request.Method, url)
+	fmt.Fprintf(&b, "Attempt: %d\n", e.Request.Attempt)
+	writeHeaders(&b, header)
+	if body, ok := client.RequestBody(e.Request); ok && body != "" {
+		b.WriteString("\n")
+		b.Write(prettyBody(client.RedactBody([]byte(body))))
+		b.WriteString("\n")
+	}
+	return b.Bytes()
+}
+
+// formatCurl 返回复现请求的 curl 命令，附件会随报告共享，请求体中敏感字段的值与请求附件一样隐藏；
+// 需要原样复现时使用 client.Curl 或 APIError.Curl
+func formatCurl(e client.Exchange) []byte {
+	curl := client.CurlCommand(e.Request)
+	if body, ok := client.RequestBody(e.Request); ok {
+		// 请求体是命令的最后一个参数
+		if redacted := string(client.RedactBody([]byte(body))); redacted != body {
+			curl = strings.TrimSuffix(curl, client.ShellQuote(body)) + client.ShellQuote(redacted)
+		}
+	}
+	return []byte(curl)
+}
+
+// formatResponse 格式化响应状态、耗时、响应头和响应体；未收到响应时记录错误
+func formatResponse(e client.Exchange) []byte {
+	var b bytes.Buffer
+	if e.Response == nil {
+		fmt.Fprintf(&b, "Error: %v\nDuration: %v\n", e.Err, e.Duration())
+		writeLimiterWait(&b, e)
+		return b.Bytes()
+	}
+	fmt.Fprintf(&b, "%s %s\n", e.Response.Proto(), e.Response.Status())
+	fmt.Fprintf(&b, "Duration: %v\n", e.Duration())
+	writeLimiterWait(&b, e)
+	if e.Err != nil {
+		fmt.Fprintf(&b, "Error: %v\n", e.Err)
+	}
+	writeHeaders(&b, e.Response.Header())
+	if body := prettyBody(e.Response.Body()); len(body) > 0 {
+		b.WriteString("\n")
+		b.Write(body)
+		b.WriteString("\n")
+	}
+	return b.Bytes()
+}
+
+// writeLimiterWait 请求等待过限速器时写入等待时间
+func writeLimiterWait(b *bytes.Buffer, e client.Exchange) {
+	if e.LimiterWait > 0 {
+		fmt.Fprintf(b, "Limiter wait: %v\n", e.LimiterWait)
+	}
+}
+
+// writeHeaders 按名称排序写入请求头，敏感值已隐藏
+func writeHeaders(b *bytes.Buffer, header http.Header) {
+	header = client.RedactHeaders(header)
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteString("\n")
+	for _, name := range names {
+		fmt.Fprintf(b, "%s: %s\n", name, strings.Join(header[name], ", "))
+	}
+}
+
+// prettyBody 把请求体或响应体格式化为缩进的 JSON，不是 JSON 时原样返回
+func prettyBody(body interface{}) []byte {
+	var raw []byte
+	switch v := body.(type) {
+	case nil:
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return []byte(fmt.Sprintf("%+v", v))
+		}
+		raw = data
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return raw
+	}
+	return out.Bytes()
+}
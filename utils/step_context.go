@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// StepContext 为 Allure 步骤创建带超时的 context，并把超时时间和截止时间记录为步骤参数
+func StepContext(sCtx provider.StepCtx, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	deadline, _ := ctx.Deadline()
+	sCtx.WithNewParameters(
+		"timeout", timeout.String(),
+		"deadline", deadline.Format("2006-01-02 15:04:05.000"),
+	)
+	return ctx, cancel
+}
@@ -4,21 +4,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"testing"
+	"strings"
 	"time"
 
+	"go-testify-allure-api-test/schema"
+
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// TestHelper 测试辅助工具结构体
+// TestingT TestHelper 依赖的测试接口，*testing.T 和 Allure 的 provider.T 都满足
+type TestingT interface {
+	require.TestingT
+	Logf(format string, args ...interface{})
+}
+
+// TestHelper 测试辅助工具结构体。
+// 除了所属用例的 t 之外不保存任何状态，可在并行用例中使用，但每个用例应创建自己的实例
 type TestHelper struct {
-	t *testing.T
+	t TestingT
 }
 
 // NewTestHelper 创建新的测试辅助工具
-func NewTestHelper(t *testing.T) *TestHelper {
+func NewTestHelper(t TestingT) *TestHelper {
 	return &TestHelper{
 		t: t,
 	}
@@ -81,7 +90,26 @@ func (h *TestHelper) AssertFieldType(data map[string]interface{}, field string,
 	}
 }
 
+// AssertSchema 断言响应体符合 JSON Schema，每处违规以 JSON Pointer 路径报告
+func (h *TestHelper) AssertSchema(resp *resty.Response, s *schema.Schema, description string) bool {
+	h.t.Logf("验证JSON Schema: %s", description)
+	violations, err := schema.ValidateResponse(resp, s)
+	if !assert.NoError(h.t, err, description) {
+		return false
+	}
+	if len(violations) == 0 {
+		return true
+	}
+	lines := make([]string, len(violations))
+	for i, v := range violations {
+		lines[i] = v.String()
+	}
+	return assert.Fail(h.t, fmt.Sprintf("%s - 响应不符合 Schema，共 %d 处违规:\n%s", description, len(violations), strings.Join(lines, "\n")))
+}
+
 // LogRequest 记录请求信息
+//
+// Deprecated: 通过 client.WithHook 注册 AllureReporter.Hook，请求会自动写入 Allure 附件
 func (h *TestHelper) LogRequest(method, url string, body interface{}) {
 	h.t.Logf("发送 %s 请求 - URL: %s", method, url)
 	if body != nil {
@@ -91,6 +119,8 @@ func (h *TestHelper) LogRequest(method, url string, body interface{}) {
 }
 
 // LogResponse 记录响应信息
+//
+// Deprecated: 通过 client.WithHook 注册 AllureReporter.Hook，响应会自动写入 Allure 附件
 func (h *TestHelper) LogResponse(resp *resty.Response) {
 	h.t.Logf("响应信息 - 状态码: %d, 响应时间: %s, 响应大小: %d bytes", 
 		resp.StatusCode(), resp.Time().String(), len(resp.Body()))
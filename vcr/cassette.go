@@ -0,0 +1,121 @@
+package vcr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatYAML = "yaml" // 以 YAML 保存磁带
+	FormatJSON = "json" // 以 JSON 保存磁带
+)
+
+// Interaction 一次录制的请求和响应
+type Interaction struct {
+	Request  Request  `yaml:"request" json:"request"`
+	Response Response `yaml:"response" json:"response"`
+}
+
+// Request 录制的请求，敏感请求头的值已隐藏
+type Request struct {
+	Method  string              `yaml:"method" json:"method"`
+	URL     string              `yaml:"url" json:"url"`
+	Headers map[string][]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Body    string              `yaml:"body,omitempty" json:"body,omitempty"`
+}
+
+// Response 录制的响应
+type Response struct {
+	Status  int                 `yaml:"status" json:"status"`
+	Headers map[string][]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Body    string              `yaml:"body,omitempty" json:"body,omitempty"`
+}
+
+// Cassette 一个用例的全部交互，按录制顺序保存。可在多个 goroutine 之间共享
+type Cassette struct {
+	Name         string        `yaml:"name" json:"name"`
+	Interactions []Interaction `yaml:"interactions" json:"interactions"`
+
+	mu   sync.Mutex
+	used []bool
+}
+
+// Path 返回磁带文件路径，format 决定扩展名
+func Path(dir, name, format string) string {
+	return filepath.Join(dir, name+"."+format)
+}
+
+// Load 读取磁带文件，按扩展名选择 YAML 或 JSON 格式
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+
+	c := &Cassette{}
+	if strings.HasSuffix(path, "."+FormatJSON) {
+		err = json.Unmarshal(data, c)
+	} else {
+		err = yaml.Unmarshal(data, c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+	}
+	c.used = make([]bool, len(c.Interactions))
+	return c, nil
+}
+
+// Save 把磁带写入 path，按扩展名选择 YAML 或 JSON 格式，必要时创建上级目录
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var data []byte
+	var err error
+	if strings.HasSuffix(path, "."+FormatJSON) {
+		data, err = json.MarshalIndent(c, "", "  ")
+	} else {
+		data, err = yaml.Marshal(c)
+	}
+	if err != nil {
+		return fmt.Errorf("encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Len 返回磁带中的交互数
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.Interactions)
+}
+
+// add 追加一次录制的交互
+func (c *Cassette) add(i Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, i)
+	c.used = append(c.used, false)
+}
+
+// take 返回第一个未使用且匹配的交互，并标记为已使用
+func (c *Cassette) take(match func(Request) bool) (Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, interaction := range c.Interactions {
+		if !c.used[i] && match(interaction.Request) {
+			c.used[i] = true
+			return interaction, true
+		}
+	}
+	return Interaction{}, false
+}
@@ -0,0 +1,93 @@
+package vcr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+
+	"go-testify-allure-api-test/client"
+)
+
+const (
+	MatchMethod = "method" // 请求方法
+	MatchPath   = "path"   // 请求路径，不比较协议和主机，录制和回放可以使用不同的地址
+	MatchQuery  = "query"  // 查询参数，与参数顺序无关
+	MatchBody   = "body"   // 请求体，JSON 按语义比较，敏感字段的值不参与比较
+)
+
+// Fields 所有可用的匹配字段
+var Fields = []string{MatchMethod, MatchPath, MatchQuery, MatchBody}
+
+// Matcher 按配置的字段判断请求与录制的请求是否匹配
+type Matcher struct {
+	fields []string
+}
+
+// NewMatcher 创建按 fields 匹配的 Matcher，fields 为空或包含未知字段时返回错误
+func NewMatcher(fields []string) (Matcher, error) {
+	if len(fields) == 0 {
+		return Matcher{}, fmt.Errorf("at least one match field is required")
+	}
+	for _, f := range fields {
+		if !isField(f) {
+			return Matcher{}, fmt.Errorf("unknown match field %q", f)
+		}
+	}
+	return Matcher{fields: fields}, nil
+}
+
+// Match 判断 actual 与录制的 recorded 是否匹配
+func (m Matcher) Match(actual, recorded Request) bool {
+	au, err1 := url.Parse(actual.URL)
+	ru, err2 := url.Parse(recorded.URL)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	for _, f := range m.fields {
+		switch f {
+		case MatchMethod:
+			if actual.Method != recorded.Method {
+				return false
+			}
+		case MatchPath:
+			if au.Path != ru.Path {
+				return false
+			}
+		case MatchQuery:
+			if !reflect.DeepEqual(au.Query(), ru.Query()) {
+				return false
+			}
+		case MatchBody:
+			if !sameBody(actual.Body, recorded.Body) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sameBody 比较隐藏敏感字段后的请求体，两者都是 JSON 时忽略字段顺序和空白。
+// 旧磁带中未隐藏的请求体也能与隐藏后的请求匹配
+func sameBody(a, b string) bool {
+	a, b = string(client.RedactBody([]byte(a))), string(client.RedactBody([]byte(b)))
+	if a == b {
+		return true
+	}
+	var av, bv interface{}
+	if json.Unmarshal([]byte(a), &av) != nil || json.Unmarshal([]byte(b), &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// isField 判断是否为可用的匹配字段
+func isField(f string) bool {
+	for _, field := range Fields {
+		if field == f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package vcr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go-testify-allure-api-test/client"
+)
+
+// Mode 磁带模式
+type Mode string
+
+const (
+	ModeRecord      Mode = "record"      // 请求真实服务并录制到磁带
+	ModeReplay      Mode = "replay"      // 只从磁带回放，未匹配的请求直接失败
+	ModePassthrough Mode = "passthrough" // 不使用磁带
+)
+
+// Modes 所有可用的模式
+var Modes = []string{string(ModeRecord), string(ModeReplay), string(ModePassthrough)}
+
+// ErrNoInteraction 回放模式下请求在磁带中没有匹配的交互
+var ErrNoInteraction = errors.New("磁带中没有匹配的请求")
+
+// Transport 录制或回放请求的 http.RoundTripper
+type Transport struct {
+	mode     Mode
+	cassette *Cassette
+	matcher  Matcher
+	next     http.RoundTripper
+
+	mu        sync.Mutex
+	unmatched []string
+}
+
+// NewTransport 创建录制或回放 cassette 的 Transport。录制模式通过 next 发送真实请求，next 为nil时使用 http.DefaultTransport
+func NewTransport(mode Mode, cassette *Cassette, matcher Matcher, next http.RoundTripper) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{mode: mode, cassette: cassette, matcher: matcher, next: next}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.mode {
+	case ModeRecord:
+		return t.record(req, recorded)
+	case ModeReplay:
+		return t.replay(req, recorded)
+	default:
+		return t.next.RoundTrip(req)
+	}
+}
+
+// Unmatched 返回回放模式下未匹配的请求，格式为 "GET /products?limit=5"
+func (t *Transport) Unmatched() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.unmatched...)
+}
+
+// record 发送真实请求并把请求和响应追加到磁带
+func (t *Transport) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.cassette.add(Interaction{
+		Request: recorded,
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: client.RedactHeaders(resp.Header),
+			Body:    string(body),
+		},
+	})
+	return resp, nil
+}
+
+// replay 返回第一个未使用的匹配交互
+func (t *Transport) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	// 与真实传输一致，已取消或超时的请求不消耗磁带
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	// 回放的结果不是目标服务返回的，不计入熔断器
+	client.MarkNotSent(req)
+	interaction, ok := t.cassette.take(func(r Request) bool {
+		return t.matcher.Match(recorded, r)
+	})
+	if !ok {
+		desc := req.Method + " " + req.URL.RequestURI()
+		t.mu.Lock()
+		t.unmatched = append(t.unmatched, desc)
+		t.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNoInteraction, desc)
+	}
+
+	client.TraceReusedConn(req)
+
+	header := http.Header(interaction.Response.Headers).Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+		StatusCode:    interaction.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// newRequest 读取请求体后还原，返回用于录制和匹配的请求。
+// 请求体中敏感字段的值已隐藏，录制和匹配都使用隐藏后的请求体，磁带中不会出现密码
+func newRequest(req *http.Request) (Request, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return Request{}, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return Request{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: client.RedactHeaders(req.Header),
+		Body:    string(client.RedactBody(body)),
+	}, nil
+}